@@ -0,0 +1,210 @@
+package superFlags
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
+	lg "github.com/superwhys/superGo/superLog"
+)
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+)
+
+// Bind registers a flag for every field of the struct pointed to by cfg and
+// fills the fields at the end of Parse, so it has to be called before Parse.
+//
+//	type Config struct {
+//		Port  int    `flag:"port" default:"8080" usage:"Listen port"`
+//		MySQL struct {
+//			Host string `flag:"host" required:"true" usage:"MySQL host"`
+//		} `flag:"mysql"`
+//	}
+//
+// The key defaults to the lower-cased field name, `flag:"-"` skips a field.
+// The fields of a nested struct get the key of the struct as prefix, e.g.
+// --mysql.host or mysql.host in the config file, embedded structs are
+// flattened. Supported types are string, bool, int, int64, uint, float64,
+// time.Duration, time.Time, []string, []int, []time.Duration and
+// map[string]string. `default` of a slice is comma separated, of a map
+// k=v pairs separated by commas, of a time.Time in RFC3339 format.
+// `enum:"a,b,c"` restricts a string to the listed values, `secret:"true"`
+// masks the value in Dump and makes a string field a Secret, whose default
+// may be a reference such as `default:"env:DB_PASS"`.
+func (fs *FlagSet) Bind(cfg interface{}) {
+	rv := reflect.ValueOf(cfg)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		lg.Fatalf("Bind expects a pointer to a struct, got %T", cfg)
+	}
+	fs.bindStruct(rv.Elem(), "")
+}
+
+func (fs *FlagSet) bindStruct(rv reflect.Value, prefix string) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := field.Tag.Get("flag")
+		if name == "-" {
+			continue
+		}
+		fv := rv.Field(i)
+		if field.Type.Kind() == reflect.Struct && field.Type != timeType {
+			if field.Anonymous && name == "" {
+				fs.bindStruct(fv, prefix)
+			} else {
+				fs.bindStruct(fv, prefix+keyOf(field, name)+".")
+			}
+			continue
+		}
+		fs.bindField(fv, field, prefix+keyOf(field, name))
+	}
+}
+
+func keyOf(field reflect.StructField, name string) string {
+	if name != "" {
+		return name
+	}
+	return strings.ToLower(field.Name)
+}
+
+func (fs *FlagSet) bindField(fv reflect.Value, field reflect.StructField, key string) {
+	def := field.Tag.Get("default")
+	usage := field.Tag.Get("usage")
+
+	var (
+		get func() interface{}
+		err error
+	)
+	switch {
+	case field.Type == durationType:
+		var d time.Duration
+		if def != "" {
+			d, err = time.ParseDuration(def)
+		}
+		getter := fs.Duration(key, d, usage)
+		get = func() interface{} { return getter() }
+	case field.Type == timeType:
+		var t time.Time
+		if def != "" {
+			t, err = time.Parse(time.RFC3339, def)
+		}
+		getter := fs.Time(key, t, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.String:
+		var getter func() string
+		if secret, _ := strconv.ParseBool(field.Tag.Get("secret")); secret {
+			getter = fs.secret(key, def, usage)
+		} else if enum := field.Tag.Get("enum"); enum != "" {
+			getter = fs.Enum(key, def, strings.Split(enum, ","), usage)
+		} else {
+			getter = fs.String(key, def, usage)
+		}
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Bool:
+		var b bool
+		if def != "" {
+			b, err = strconv.ParseBool(def)
+		}
+		getter := fs.Bool(key, b, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Int:
+		var n int
+		if def != "" {
+			n, err = strconv.Atoi(def)
+		}
+		getter := fs.Int(key, n, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Int64:
+		var n int64
+		if def != "" {
+			n, err = strconv.ParseInt(def, 10, 64)
+		}
+		getter := fs.Int64(key, n, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Uint:
+		var n uint64
+		if def != "" {
+			n, err = strconv.ParseUint(def, 10, 0)
+		}
+		getter := fs.Uint(key, uint(n), usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Float64:
+		var f float64
+		if def != "" {
+			f, err = strconv.ParseFloat(def, 64)
+		}
+		getter := fs.Float64(key, f, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.String:
+		var s []string
+		if def != "" {
+			s = strings.Split(def, ",")
+		}
+		getter := fs.Slice(key, s, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Slice && field.Type.Elem().Kind() == reflect.Int:
+		var n []int
+		if def != "" {
+			n, err = cast.ToIntSliceE(strings.Split(def, ","))
+		}
+		getter := fs.IntSlice(key, n, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Slice && field.Type.Elem() == durationType:
+		var d []time.Duration
+		if def != "" {
+			d, err = cast.ToDurationSliceE(strings.Split(def, ","))
+		}
+		getter := fs.DurationSlice(key, d, usage)
+		get = func() interface{} { return getter() }
+	case field.Type.Kind() == reflect.Map && field.Type.Key().Kind() == reflect.String && field.Type.Elem().Kind() == reflect.String:
+		var m map[string]string
+		if def != "" {
+			m, err = parseStringMap(def)
+		}
+		getter := fs.StringMap(key, m, usage)
+		get = func() interface{} { return getter() }
+	default:
+		lg.Fatalf("Bind unsupported type %s of field %s, key: --%s", field.Type, field.Name, key)
+	}
+	if err != nil {
+		lg.Fatalf("Invalid default of --%s: %v", key, err)
+	}
+
+	if required, _ := strconv.ParseBool(field.Tag.Get("required")); required {
+		fs.required(key)
+	}
+	if secret, _ := strconv.ParseBool(field.Tag.Get("secret")); secret {
+		fs.MarkSecret(key)
+	}
+	fs.bindings = append(fs.bindings, func() {
+		fv.Set(reflect.ValueOf(get()).Convert(fv.Type()))
+	})
+}
+
+// parseStringMap parses k=v pairs separated by commas.
+func parseStringMap(s string) (map[string]string, error) {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("%q is not a k=v pair", pair)
+		}
+		m[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+	return m, nil
+}
+
+// fillBindings sets the fields registered by Bind from the parsed values.
+func (fs *FlagSet) fillBindings() {
+	for _, fill := range fs.bindings {
+		fill()
+	}
+}
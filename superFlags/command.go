@@ -0,0 +1,155 @@
+package superFlags
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	lg "github.com/superwhys/superGo/superLog"
+)
+
+type command struct {
+	name  string
+	usage string
+	flags *FlagSet
+	run   func(args []string) error
+}
+
+var commands []*command
+
+// Command registers a subcommand run by Execute and returns the set to
+// declare its own flags on, e.g.
+//
+//	migrate := superFlags.Command("migrate", "Run the database migrations", runMigrate)
+//	steps := migrate.Int("steps", 0, "The number of migrations to run, 0 runs all")
+//
+// The global flags, --config and --debug included, are accepted before and
+// after the name of the command, e.g. `app -f config.yaml migrate --steps 1`.
+// A command shares the config file and the env binding of CommandLine.
+func Command(name, usage string, run func(args []string) error) *FlagSet {
+	if run == nil {
+		lg.Fatalf("Command %s has no run func", name)
+	}
+	for _, c := range commands {
+		if c.name == name {
+			lg.Fatalf("Command %s registered twice", name)
+		}
+	}
+	fs := newSet(name, pflag.NewFlagSet(name, pflag.ContinueOnError))
+	fs.parent = CommandLine
+	fs.config = CommandLine.config
+	fs.configWatch = CommandLine.configWatch
+	commands = append(commands, &command{name: name, usage: usage, flags: fs, run: run})
+	return fs
+}
+
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// related returns the sets sharing the config file of fs.
+func (fs *FlagSet) related() []*FlagSet {
+	sets := []*FlagSet{fs}
+	if fs == CommandLine {
+		for _, c := range commands {
+			sets = append(sets, c.flags)
+		}
+	}
+	return sets
+}
+
+// Execute parses os.Args, dispatches to the command named by the first
+// argument which is not a flag and returns once its Run func succeeds.
+// `app help` and `app --help` list the commands, `app help migrate` and
+// `app migrate --help` print the flags of a command.
+func Execute() {
+	initFlags()
+	root := CommandLine.flags
+	root.SetInterspersed(false)
+	root.Usage = printCommands
+	if err := root.Parse(os.Args[1:]); err != nil {
+		lg.Fatal(err.Error())
+	}
+
+	args := root.Args()
+	if len(args) == 0 {
+		printCommands()
+		os.Exit(2)
+	}
+	if args[0] == "help" {
+		if len(args) > 1 && findCommand(args[1]) != nil {
+			printCommand(findCommand(args[1]))
+		} else {
+			printCommands()
+		}
+		os.Exit(0)
+	}
+	cmd := findCommand(args[0])
+	if cmd == nil {
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+		printCommands()
+		os.Exit(2)
+	}
+
+	// The flags are shared, values parsed here are seen by both sets.
+	merged := pflag.NewFlagSet(cmd.name, pflag.ContinueOnError)
+	merged.AddFlagSet(cmd.flags.flags)
+	merged.AddFlagSet(root)
+	merged.Usage = func() { printCommand(cmd) }
+	if err := merged.Parse(args[1:]); err != nil {
+		if err == pflag.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		printCommand(cmd)
+		os.Exit(2)
+	}
+
+	cmd.flags.envEnabled = CommandLine.envEnabled
+	cmd.flags.envPrefix = CommandLine.envPrefix
+	setup(CommandLine, cmd.flags)
+
+	if err := cmd.run(merged.Args()); err != nil {
+		lg.Fatal(errors.Wrapf(err, "Run %s", cmd.name))
+	}
+}
+
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
+
+func printCommands() {
+	w := os.Stderr
+	fmt.Fprintf(w, "Usage: %s [global flags] <command> [flags]\n\nCommands:\n", programName())
+	width := 0
+	for _, c := range commands {
+		if len(c.name) > width {
+			width = len(c.name)
+		}
+	}
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-*s  %s\n", width, c.name, c.usage)
+	}
+	fmt.Fprintf(w, "\nGlobal flags:\n%s\n", CommandLine.flags.FlagUsages())
+	fmt.Fprintf(w, "Run '%s help <command>' for the flags of a command.\n", programName())
+}
+
+func printCommand(c *command) {
+	w := os.Stderr
+	fmt.Fprintf(w, "Usage: %s %s [flags]\n\n", programName(), c.name)
+	if c.usage != "" {
+		fmt.Fprintf(w, "%s\n\n", c.usage)
+	}
+	if usages := c.flags.flags.FlagUsages(); strings.TrimSpace(usages) != "" {
+		fmt.Fprintf(w, "Flags:\n%s\n", usages)
+	}
+	fmt.Fprintf(w, "Global flags:\n%s", CommandLine.flags.FlagUsages())
+}
@@ -0,0 +1,149 @@
+package superFlags
+
+import "time"
+
+// The functions below declare the flags of CommandLine.
+
+func String(key, defaultValue, usage string) func() string {
+	return CommandLine.String(key, defaultValue, usage)
+}
+
+func StringRequired(key, usage string) func() string {
+	return CommandLine.StringRequired(key, usage)
+}
+
+func Bool(key string, defaultValue bool, usage string) func() bool {
+	return CommandLine.Bool(key, defaultValue, usage)
+}
+
+func BoolRequired(key, usage string) func() bool {
+	return CommandLine.BoolRequired(key, usage)
+}
+
+func Int(key string, defaultValue int, usage string) func() int {
+	return CommandLine.Int(key, defaultValue, usage)
+}
+
+func IntRequired(key, usage string) func() int {
+	return CommandLine.IntRequired(key, usage)
+}
+
+func Slice(key string, defaultValue []string, usage string) func() []string {
+	return CommandLine.Slice(key, defaultValue, usage)
+}
+
+func Float64(key string, defaultValue float64, usage string) func() float64 {
+	return CommandLine.Float64(key, defaultValue, usage)
+}
+
+func Float64Required(key, usage string) func() float64 {
+	return CommandLine.Float64Required(key, usage)
+}
+
+func Duration(key string, defaultValue time.Duration, usage string) func() time.Duration {
+	return CommandLine.Duration(key, defaultValue, usage)
+}
+
+func DurationRequired(key, usage string) func() time.Duration {
+	return CommandLine.DurationRequired(key, usage)
+}
+
+func Int64(key string, defaultValue int64, usage string) func() int64 {
+	return CommandLine.Int64(key, defaultValue, usage)
+}
+
+func Int64Required(key, usage string) func() int64 {
+	return CommandLine.Int64Required(key, usage)
+}
+
+func Uint(key string, defaultValue uint, usage string) func() uint {
+	return CommandLine.Uint(key, defaultValue, usage)
+}
+
+func UintRequired(key, usage string) func() uint {
+	return CommandLine.UintRequired(key, usage)
+}
+
+func IntSlice(key string, defaultValue []int, usage string) func() []int {
+	return CommandLine.IntSlice(key, defaultValue, usage)
+}
+
+func IntSliceRequired(key, usage string) func() []int {
+	return CommandLine.IntSliceRequired(key, usage)
+}
+
+func DurationSlice(key string, defaultValue []time.Duration, usage string) func() []time.Duration {
+	return CommandLine.DurationSlice(key, defaultValue, usage)
+}
+
+func DurationSliceRequired(key, usage string) func() []time.Duration {
+	return CommandLine.DurationSliceRequired(key, usage)
+}
+
+func StringMap(key string, defaultValue map[string]string, usage string) func() map[string]string {
+	return CommandLine.StringMap(key, defaultValue, usage)
+}
+
+func StringMapRequired(key, usage string) func() map[string]string {
+	return CommandLine.StringMapRequired(key, usage)
+}
+
+func Time(key string, defaultValue time.Time, usage string) func() time.Time {
+	return CommandLine.Time(key, defaultValue, usage)
+}
+
+func TimeRequired(key, usage string) func() time.Time {
+	return CommandLine.TimeRequired(key, usage)
+}
+
+func Enum(key, defaultValue string, allowed []string, usage string) func() string {
+	return CommandLine.Enum(key, defaultValue, allowed, usage)
+}
+
+func EnumRequired(key string, allowed []string, usage string) func() string {
+	return CommandLine.EnumRequired(key, allowed, usage)
+}
+
+func Secret(key, usage string) func() string {
+	return CommandLine.Secret(key, usage)
+}
+
+func SecretRequired(key, usage string) func() string {
+	return CommandLine.SecretRequired(key, usage)
+}
+
+func Bind(cfg interface{}) {
+	CommandLine.Bind(cfg)
+}
+
+func EnableEnv(prefix string) {
+	CommandLine.EnableEnv(prefix)
+}
+
+func EnvName(key string) string {
+	return CommandLine.EnvName(key)
+}
+
+func Sub(name string) *Section {
+	return CommandLine.Sub(name)
+}
+
+func MarkSecret(key string) {
+	CommandLine.MarkSecret(key)
+}
+
+func OnChange(key string, fn func(old, new interface{})) {
+	CommandLine.OnChange(key, fn)
+}
+
+func IntRange(key string, min, max int) {
+	CommandLine.IntRange(key, min, max)
+}
+
+func Regex(key, expr string) {
+	CommandLine.Regex(key, expr)
+}
+
+func Validate(key string, fn func(value interface{}) error) {
+	CommandLine.Validate(key, fn)
+}
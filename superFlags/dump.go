@@ -0,0 +1,199 @@
+package superFlags
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	lg "github.com/superwhys/superGo/superLog"
+	"gopkg.in/yaml.v2"
+)
+
+// The sources of a value, by precedence.
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// ConfigValue is the effective value of a key and where it comes from.
+type ConfigValue struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Source string      `json:"source" yaml:"source"`
+}
+
+var (
+	dumpConfig *string
+	// parsedSets are the sets set up by Parse or Execute, dumped by Dump.
+	parsedSets []*FlagSet
+)
+
+// MarkSecret masks the value of key in Dump, e.g. for a password flag.
+func (fs *FlagSet) MarkSecret(key string) {
+	if fs.secretKeys == nil {
+		fs.secretKeys = map[string]bool{}
+	}
+	fs.secretKeys[strings.ToLower(key)] = true
+}
+
+// Values returns every key declared in fs with its current value and
+// source, sorted by key. Secret values are masked.
+func (fs *FlagSet) Values() []ConfigValue {
+	return fs.values(fs.load())
+}
+
+func (fs *FlagSet) values(vp *viper.Viper) []ConfigValue {
+	seen := map[string]bool{}
+	var values []ConfigValue
+	for _, key := range fs.allKeys {
+		lower := strings.ToLower(key)
+		if seen[lower] {
+			continue
+		}
+		seen[lower] = true
+
+		source := fs.source(vp, key)
+		value := vp.Get(key)
+		if flag := fs.flags.Lookup(key); value == nil && flag != nil {
+			// Flags such as --debug are not bound to viper.
+			value = flag.Value.String()
+		}
+		if value == nil && source == SourceDefault {
+			continue
+		}
+		values = append(values, ConfigValue{Key: key, Value: fs.printable(key, value), Source: source})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Key < values[j].Key
+	})
+	return values
+}
+
+// source follows the precedence flag > env > file > default.
+func (fs *FlagSet) source(vp *viper.Viper, key string) string {
+	if flag := fs.flags.Lookup(key); flag != nil && flag.Changed {
+		return SourceFlag
+	}
+	if fs.envEnabled {
+		if val, ok := os.LookupEnv(fs.EnvName(key)); ok && val != "" {
+			return SourceEnv
+		}
+	}
+	if vp.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// printable masks secrets and renders durations and times as text.
+func (fs *FlagSet) printable(key string, value interface{}) interface{} {
+	if fs.secretKeys[strings.ToLower(key)] {
+		if ref, ok := value.(string); ok && (ref == "" || isSecretRef(ref)) {
+			return ref
+		}
+		return lg.RedactedValue
+	}
+	switch val := value.(type) {
+	case time.Duration:
+		return val.String()
+	case []time.Duration:
+		ret := make([]string, len(val))
+		for i, d := range val {
+			ret[i] = d.String()
+		}
+		return ret
+	case time.Time:
+		return val.Format(time.RFC3339)
+	case string:
+		return lg.Redact(val)
+	}
+	return value
+}
+
+// Dump writes the effective config of fs in yaml or json.
+func (fs *FlagSet) Dump(w io.Writer, format string) error {
+	return writeValues(w, format, fs.Values())
+}
+
+// Dump writes the effective config parsed by Parse or Execute, in yaml or
+// json, with the source of every value.
+func Dump(w io.Writer, format string) error {
+	return writeValues(w, format, dumpValues())
+}
+
+func dumpValues() []ConfigValue {
+	sets := parsedSets
+	if len(sets) == 0 {
+		sets = []*FlagSet{CommandLine}
+	}
+	var values []ConfigValue
+	for _, fs := range sets {
+		values = append(values, fs.Values()...)
+	}
+	return values
+}
+
+func writeValues(w io.Writer, format string, values []ConfigValue) error {
+	var (
+		data []byte
+		err  error
+	)
+	switch strings.ToLower(format) {
+	case "json":
+		data, err = json.MarshalIndent(values, "", "  ")
+		data = append(data, '\n')
+	case "yaml", "yml", "":
+		data, err = yaml.Marshal(values)
+	default:
+		return errors.Errorf("unknown dump format: %q, expect yaml or json", format)
+	}
+	if err != nil {
+		return errors.Wrap(err, "Encode config")
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// DumpHandler returns an http.Handler serving Dump, as json by default or
+// as yaml with ?format=yaml.
+func DumpHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "json"
+		}
+		if format == "json" {
+			w.Header().Set("Content-Type", "application/json")
+		} else {
+			w.Header().Set("Content-Type", "text/yaml; charset=utf-8")
+		}
+		if err := Dump(w, format); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	})
+}
+
+// dumpAndExit prints the config and exits when --dump-config is set.
+func dumpAndExit(sets []*FlagSet) {
+	if dumpConfig == nil || *dumpConfig == "" {
+		return
+	}
+	var values []ConfigValue
+	for _, fs := range sets {
+		values = append(values, fs.values(fs.v)...)
+	}
+	if err := writeValues(os.Stdout, *dumpConfig, values); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,100 @@
+package superFlags
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+	lg "github.com/superwhys/superGo/superLog"
+)
+
+// EnableEnv makes Parse also read every key from an environment variable,
+// e.g. --serviceName from SERVICE_NAME and mysql.host from MYSQL_HOST.
+// A non empty prefix is prepended with an underscore: MYAPP_MYSQL_HOST.
+// It has to be called before Parse.
+//
+// The precedence is flag > env > config file > default. Slices are comma
+// separated and maps are k=v pairs separated by commas.
+func (fs *FlagSet) EnableEnv(prefix string) {
+	fs.envEnabled = true
+	fs.envPrefix = strings.Trim(prefix, "_")
+}
+
+// EnvName returns the environment variable read for key.
+func (fs *FlagSet) EnvName(key string) string {
+	var b strings.Builder
+	if fs.envPrefix != "" {
+		b.WriteString(strings.ToUpper(fs.envPrefix))
+		b.WriteByte('_')
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case r == '.' || r == '-':
+			b.WriteByte('_')
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	return b.String()
+}
+
+// bindEnv binds every declared key of vp to its environment variable.
+func (fs *FlagSet) bindEnv(vp *viper.Viper) {
+	if !fs.envEnabled {
+		return
+	}
+	for _, key := range fs.allKeys {
+		if err := vp.BindEnv(key, fs.EnvName(key)); err != nil {
+			lg.Fatalf("BindEnv err, Key: --%s", key)
+		}
+	}
+}
+
+// The values below may be a plain string when read from the environment.
+
+func toStringSlice(i interface{}) []string {
+	if s, ok := i.(string); ok {
+		return splitList(s)
+	}
+	return cast.ToStringSlice(i)
+}
+
+func toIntSlice(i interface{}) ([]int, error) {
+	if s, ok := i.(string); ok {
+		return cast.ToIntSliceE(splitList(s))
+	}
+	return cast.ToIntSliceE(i)
+}
+
+func toStringMap(i interface{}) map[string]string {
+	if s, ok := i.(string); ok && !strings.HasPrefix(strings.TrimSpace(s), "{") {
+		if strings.TrimSpace(s) == "" {
+			return map[string]string{}
+		}
+		m, err := parseStringMap(s)
+		if err == nil {
+			return m
+		}
+	}
+	return cast.ToStringMapString(i)
+}
+
+func splitList(s string) []string {
+	s = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(s), "["), "]")
+	if strings.TrimSpace(s) == "" {
+		return nil
+	}
+	items := strings.Split(s, ",")
+	for i := range items {
+		items[i] = strings.TrimSpace(items[i])
+	}
+	return items
+}
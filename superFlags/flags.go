@@ -1,10 +1,16 @@
 package superFlags
 
 import (
+	"fmt"
 	"github.com/superwhys/superGo/superSlices"
+	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/spf13/cast"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	lg "github.com/superwhys/superGo/superLog"
@@ -12,70 +18,178 @@ import (
 	_ "github.com/spf13/viper/remote"
 )
 
-var (
+// FlagSet is a set of flags with its own command line, config file,
+// environment binding and validators. The package-level functions use
+// CommandLine, libraries and tests can create isolated sets with NewSet.
+type FlagSet struct {
+	name  string
+	flags *pflag.FlagSet
+	v     *viper.Viper
+	// current is the viper swapped in by the last valid reload.
+	current atomic.Value
+	// defaults keeps the default of every key to build a new viper on reload.
+	defaults map[string]interface{}
+
 	allKeys     []string
 	requiredKey []string
+	mapKeys     []string
+	// checks validate the values once all the sources are merged.
+	checks []func(vp *viper.Viper) error
+	// bindings fill the fields registered by Bind once Parse is done.
+	bindings []func()
+
+	envEnabled bool
+	envPrefix  string
+	secretKeys map[string]bool
+
 	config      *string
-	debug       *bool
+	configWatch *bool
+	// parent is the set of the global flags of a subcommand set.
+	parent *FlagSet
 
-	v = viper.New()
-)
+	subsMu   sync.Mutex
+	subs     []subscription
+	reloadMu sync.Mutex
+	// watchers stop the watches of the config file and the secret files.
+	watchMu  sync.Mutex
+	watchers []func()
+}
 
-func initFlags() {
-	v.AddConfigPath(".")
-	v.AddConfigPath("./tmp/config/")
-	err := v.BindPFlags(pflag.CommandLine)
-	if err != nil {
-		lg.Fatal("BindPFlags Error!")
+// CommandLine is the default set, parsed from os.Args by Parse.
+var CommandLine = newSet(os.Args[0], pflag.CommandLine).withConfigFlags(true)
+
+var debug *bool
+
+// NewSet returns an empty set. Its flags are parsed by fs.Parse, not from
+// os.Args, and errors are returned instead of exiting. The config file is
+// only watched with --config-watch, call fs.Close to stop watching.
+func NewSet(name string) *FlagSet {
+	return newSet(name, pflag.NewFlagSet(name, pflag.ContinueOnError)).withConfigFlags(false)
+}
+
+func newSet(name string, flags *pflag.FlagSet) *FlagSet {
+	fs := &FlagSet{
+		name:     name,
+		flags:    flags,
+		v:        viper.New(),
+		defaults: map[string]interface{}{},
 	}
-	config = pflag.StringP("config", "f", "", "Specify config file to parse. Support json, yaml, toml etc.")
+	fs.v.AddConfigPath(".")
+	fs.v.AddConfigPath("./tmp/config/")
+	return fs
+}
+
+func (fs *FlagSet) withConfigFlags(watch bool) *FlagSet {
+	fs.config = fs.flags.StringP("config", "f", "", "Specify config file to parse. Support json, yaml, toml etc.")
+	fs.configWatch = fs.flags.Bool("config-watch", watch, "Reload --config when it changes")
+	fs.allKeys = append(fs.allKeys, "config-watch")
+	return fs
+}
+
+func (fs *FlagSet) Name() string {
+	return fs.name
+}
+
+// FlagSet returns the underlying pflag set, e.g. to print its usage.
+func (fs *FlagSet) FlagSet() *pflag.FlagSet {
+	return fs.flags
+}
+
+func initFlags() {
 	debug = pflag.Bool("debug", false, "Set true to enable debug mode")
+	dumpConfig = pflag.String("dump-config", "", "Print the effective config with the source of every value and exit. Support yaml and json")
+	pflag.Lookup("dump-config").NoOptDefVal = "yaml"
+	initLogFlags()
 
-	allKeys = append(allKeys, "debug", "owner")
+	CommandLine.allKeys = append(CommandLine.allKeys, "debug", "owner")
 }
 
 // Parse has to called after main() before any application code.
 func Parse() {
 	initFlags()
-	pflag.Parse()
-	if *debug {
-		lg.EnableDebug()
+	if err := CommandLine.flags.Parse(os.Args[1:]); err != nil {
+		lg.Fatal(err.Error())
 	}
+	setup(CommandLine)
+}
 
-	for _, k := range requiredKey {
-		if isZero(v.Get(k)) {
-			lg.Fatal("Missing", k)
-		}
+// setup reads the env and the config file of the parsed sets, validates
+// them all at once and sets up superLog from the global flags.
+func setup(sets ...*FlagSet) {
+	for _, fs := range sets {
+		fs.bindEnv(fs.v)
 	}
-	expectedKeys := superSlices.NewStringSet(nil)
-	for _, k := range allKeys {
-		if err := expectedKeys.Add(strings.ToLower(k)); err != nil {
-			lg.Fatalf("Add Key Error: --%s", k)
+	setupLog(*debug)
+
+	var problems []string
+	configRead := true
+	for _, fs := range sets {
+		if err := fs.readConfig(); err != nil {
+			lg.Errorf("Failed to read on local file: %v", err)
+			configRead = false
 		}
+		problems = append(problems, fs.problems(fs.v)...)
+	}
+	dumpAndExit(sets)
+	if len(problems) > 0 {
+		lg.Fatal((&ValidationError{Problems: problems}).Error())
 	}
 
-	for _, k := range v.AllKeys() {
-		if strings.Contains(k, ".") {
-			// Ignore nested key
-			continue
-		}
-		if !expectedKeys.Contains(k) {
-			lg.Fatalf("Unknown flag in config: --%s", k)
-		}
+	setupLog(*debug || CommandLine.v.GetBool("debug"))
+	setupLogSinks()
+	setupLogAsync()
+	for _, fs := range sets {
+		fs.fillBindings()
 	}
+	parsedSets = sets
 
-	if config != nil && *config != "" {
-		v.SetConfigFile(*config)
-		if err := v.ReadInConfig(); err != nil {
-			lg.Errorf("Failed to read on local file, ", err)
-		} else {
-			lg.Infof("Read config from local file: %v!", *config)
+	if *CommandLine.config != "" && *CommandLine.configWatch && configRead {
+		watchLogFlags()
+		for _, fs := range sets {
+			fs.watchConfig(*fs.config)
 		}
 	}
+}
 
-	if v.GetBool("debug") {
-		lg.EnableDebug()
+// Parse parses args, without the program name, then reads the env and the
+// config file and validates the result. Unlike the package-level Parse it
+// does not set up superLog.
+func (fs *FlagSet) Parse(args []string) error {
+	if err := fs.flags.Parse(args); err != nil {
+		return errors.Wrapf(err, "Parse %s flags", fs.name)
+	}
+	fs.bindEnv(fs.v)
+	if err := fs.readConfig(); err != nil {
+		return err
+	}
+	if err := fs.validate(fs.v); err != nil {
+		return err
+	}
+	fs.fillBindings()
+
+	if *fs.config != "" && *fs.configWatch {
+		fs.watchConfig(*fs.config)
+	}
+	return nil
+}
+
+// Args returns the arguments left after the flags.
+func (fs *FlagSet) Args() []string {
+	return fs.flags.Args()
+}
+
+func (fs *FlagSet) readConfig() error {
+	if *fs.config == "" {
+		return nil
+	}
+	fs.v.SetConfigFile(*fs.config)
+	if err := fs.v.ReadInConfig(); err != nil {
+		return errors.Wrapf(err, "Read config %s", *fs.config)
+	}
+	if fs.parent == nil {
+		lg.Infof("Read config from local file: %v!", *fs.config)
 	}
+	return nil
 }
 
 func isZero(i interface{}) bool {
@@ -91,119 +205,264 @@ func isZero(i interface{}) bool {
 		return i.(float64) == 0
 	case int:
 		return i.(int) == 0
+	case int64:
+		return i.(int64) == 0
+	case uint:
+		return i.(uint) == 0
+	case time.Time:
+		return i.(time.Time).IsZero()
 	case []string:
 		return len(i.([]string)) == 0
+	case []int:
+		return len(i.([]int)) == 0
+	case []time.Duration:
+		return len(i.([]time.Duration)) == 0
 	case []interface{}:
 		return len(i.([]interface{})) == 0
+	case map[string]string:
+		return len(i.(map[string]string)) == 0
+	case map[string]interface{}:
+		return len(i.(map[string]interface{})) == 0
 	default:
 		return true
 	}
 }
 
-func String(key, defaultValue, usage string) func() string {
-	pflag.String(key, defaultValue, usage)
-	v.SetDefault(key, defaultValue)
-	err := v.BindPFlag(key, pflag.Lookup(key))
+// register binds the flag just defined for key to viper.
+func (fs *FlagSet) register(key string, defaultValue interface{}) {
+	fs.defaults[key] = defaultValue
+	fs.v.SetDefault(key, defaultValue)
+	err := fs.v.BindPFlag(key, fs.flags.Lookup(key))
 	if err != nil {
 		lg.Fatalf("BindPFlag err, Key: --%s", key)
 	}
-	allKeys = append(allKeys, key)
+	fs.allKeys = append(fs.allKeys, key)
+}
+
+func (fs *FlagSet) required(key string) {
+	fs.requiredKey = append(fs.requiredKey, key)
+}
+
+func (fs *FlagSet) String(key, defaultValue, usage string) func() string {
+	fs.flags.String(key, defaultValue, usage)
+	fs.register(key, defaultValue)
 	return func() string {
-		return v.GetString(key)
+		return fs.load().GetString(key)
 	}
 }
 
-func StringRequired(key, usage string) func() string {
-	requiredKey = append(requiredKey, key)
-	allKeys = append(allKeys, key)
-	return String(key, "", usage)
+func (fs *FlagSet) StringRequired(key, usage string) func() string {
+	fs.required(key)
+	return fs.String(key, "", usage)
 }
 
-func Bool(key string, defaultValue bool, usage string) func() bool {
-	pflag.Bool(key, defaultValue, usage)
-	v.SetDefault(key, defaultValue)
-	err := v.BindPFlag(key, pflag.Lookup(key))
-	if err != nil {
-		lg.Fatalf("BindPFlag err, Key: --%s", key)
-	}
-	allKeys = append(allKeys, key)
+func (fs *FlagSet) Bool(key string, defaultValue bool, usage string) func() bool {
+	fs.flags.Bool(key, defaultValue, usage)
+	fs.register(key, defaultValue)
 	return func() bool {
-		return v.GetBool(key)
+		return fs.load().GetBool(key)
 	}
 }
 
-func BoolRequired(key, usage string) func() bool {
-	requiredKey = append(requiredKey, key)
-	allKeys = append(allKeys, key)
-	return Bool(key, false, usage)
+func (fs *FlagSet) BoolRequired(key, usage string) func() bool {
+	fs.required(key)
+	return fs.Bool(key, false, usage)
 }
 
-func Int(key string, defaultValue int, usage string) func() int {
-	pflag.Int(key, defaultValue, usage)
-	v.SetDefault(key, defaultValue)
-	err := v.BindPFlag(key, pflag.Lookup(key))
-	if err != nil {
-		lg.Fatalf("BindPFlag err, Key: --%s", key)
-	}
-	allKeys = append(allKeys, key)
+func (fs *FlagSet) Int(key string, defaultValue int, usage string) func() int {
+	fs.flags.Int(key, defaultValue, usage)
+	fs.register(key, defaultValue)
 	return func() int {
-		return v.GetInt(key)
+		return fs.load().GetInt(key)
 	}
 }
 
-func IntRequired(key, usage string) func() int {
-	requiredKey = append(requiredKey, key)
-	allKeys = append(allKeys, key)
-	return Int(key, 0, usage)
+func (fs *FlagSet) IntRequired(key, usage string) func() int {
+	fs.required(key)
+	return fs.Int(key, 0, usage)
 }
 
-func Slice(key string, defaultValue []string, usage string) func() []string {
-	pflag.StringSlice(key, defaultValue, usage)
-	v.SetDefault(key, defaultValue)
-	err := v.BindPFlag(key, pflag.Lookup(key))
-	if err != nil {
-		lg.Fatalf("BindPFlag err, Key: --%s", key)
-	}
-	allKeys = append(allKeys, key)
+func (fs *FlagSet) Slice(key string, defaultValue []string, usage string) func() []string {
+	fs.flags.StringSlice(key, defaultValue, usage)
+	fs.register(key, defaultValue)
 	return func() []string {
-		return v.GetStringSlice(key)
+		return toStringSlice(fs.load().Get(key))
 	}
 }
 
-func Float64(key string, defaultValue float64, usage string) func() float64 {
-	pflag.Float64(key, defaultValue, usage)
-	v.SetDefault(key, defaultValue)
-	err := v.BindPFlag(key, pflag.Lookup(key))
-	if err != nil {
-		lg.Fatalf("BindPFlag err, Key: --%s", key)
-	}
-	allKeys = append(allKeys, key)
+func (fs *FlagSet) Float64(key string, defaultValue float64, usage string) func() float64 {
+	fs.flags.Float64(key, defaultValue, usage)
+	fs.register(key, defaultValue)
 	return func() float64 {
-		return v.GetFloat64(key)
+		return fs.load().GetFloat64(key)
 	}
 }
 
-func Float64Required(key, usage string) func() float64 {
-	requiredKey = append(requiredKey, key)
-	allKeys = append(allKeys, key)
-	return Float64(key, 0, usage)
+func (fs *FlagSet) Float64Required(key, usage string) func() float64 {
+	fs.required(key)
+	return fs.Float64(key, 0, usage)
 }
 
-func Duration(key string, defaultValue time.Duration, usage string) func() time.Duration {
-	pflag.Duration(key, defaultValue, usage)
-	v.SetDefault(key, defaultValue)
-	err := v.BindPFlag(key, pflag.Lookup(key))
-	if err != nil {
-		lg.Fatalf("BindPFlag err, Key: --%s", key)
-	}
-	allKeys = append(allKeys, key)
+func (fs *FlagSet) Duration(key string, defaultValue time.Duration, usage string) func() time.Duration {
+	fs.flags.Duration(key, defaultValue, usage)
+	fs.register(key, defaultValue)
 	return func() time.Duration {
-		return v.GetDuration(key)
+		return fs.load().GetDuration(key)
+	}
+}
+
+func (fs *FlagSet) DurationRequired(key, usage string) func() time.Duration {
+	fs.required(key)
+	return fs.Duration(key, 0, usage)
+}
+
+func (fs *FlagSet) Int64(key string, defaultValue int64, usage string) func() int64 {
+	fs.flags.Int64(key, defaultValue, usage)
+	fs.register(key, defaultValue)
+	return func() int64 {
+		return fs.load().GetInt64(key)
+	}
+}
+
+func (fs *FlagSet) Int64Required(key, usage string) func() int64 {
+	fs.required(key)
+	return fs.Int64(key, 0, usage)
+}
+
+func (fs *FlagSet) Uint(key string, defaultValue uint, usage string) func() uint {
+	fs.flags.Uint(key, defaultValue, usage)
+	fs.register(key, defaultValue)
+	fs.addCheck(func(vp *viper.Viper) error {
+		_, err := cast.ToUintE(vp.Get(key))
+		return errors.Wrapf(err, "Invalid --%s", key)
+	})
+	return func() uint {
+		return fs.load().GetUint(key)
+	}
+}
+
+func (fs *FlagSet) UintRequired(key, usage string) func() uint {
+	fs.required(key)
+	return fs.Uint(key, 0, usage)
+}
+
+func (fs *FlagSet) IntSlice(key string, defaultValue []int, usage string) func() []int {
+	fs.flags.IntSlice(key, defaultValue, usage)
+	fs.register(key, defaultValue)
+	fs.addCheck(func(vp *viper.Viper) error {
+		_, err := toIntSlice(vp.Get(key))
+		return errors.Wrapf(err, "Invalid --%s", key)
+	})
+	return func() []int {
+		n, _ := toIntSlice(fs.load().Get(key))
+		return n
+	}
+}
+
+func (fs *FlagSet) IntSliceRequired(key, usage string) func() []int {
+	fs.required(key)
+	return fs.IntSlice(key, nil, usage)
+}
+
+func (fs *FlagSet) DurationSlice(key string, defaultValue []time.Duration, usage string) func() []time.Duration {
+	fs.flags.DurationSlice(key, defaultValue, usage)
+	fs.register(key, defaultValue)
+	fs.addCheck(func(vp *viper.Viper) error {
+		_, err := toDurationSlice(vp.Get(key))
+		return errors.Wrapf(err, "Invalid --%s", key)
+	})
+	return func() []time.Duration {
+		d, _ := toDurationSlice(fs.load().Get(key))
+		return d
+	}
+}
+
+func (fs *FlagSet) DurationSliceRequired(key, usage string) func() []time.Duration {
+	fs.required(key)
+	return fs.DurationSlice(key, nil, usage)
+}
+
+// toDurationSlice also accepts the "[1s,2s]" form returned by viper for the
+// flag value, and a comma separated string from the config file or env.
+func toDurationSlice(i interface{}) ([]time.Duration, error) {
+	s, ok := i.(string)
+	if !ok {
+		return cast.ToDurationSliceE(i)
 	}
+	return cast.ToDurationSliceE(splitList(s))
+}
+
+// StringMap is a flag of k=v pairs, e.g. --labels=env=prod,zone=a, or a
+// section of the config file.
+func (fs *FlagSet) StringMap(key string, defaultValue map[string]string, usage string) func() map[string]string {
+	fs.flags.StringToString(key, defaultValue, usage)
+	fs.register(key, defaultValue)
+	fs.mapKeys = append(fs.mapKeys, key)
+	return func() map[string]string {
+		return toStringMap(fs.load().Get(key))
+	}
+}
+
+func (fs *FlagSet) StringMapRequired(key, usage string) func() map[string]string {
+	fs.required(key)
+	return fs.StringMap(key, nil, usage)
+}
+
+// Time is a flag in RFC3339 format, e.g. 2006-01-02T15:04:05Z07:00.
+// The zero defaultValue leaves the flag empty.
+func (fs *FlagSet) Time(key string, defaultValue time.Time, usage string) func() time.Time {
+	var def string
+	if !defaultValue.IsZero() {
+		def = defaultValue.Format(time.RFC3339)
+	}
+	fs.flags.String(key, def, usage)
+	fs.register(key, def)
+	fs.addCheck(func(vp *viper.Viper) error {
+		_, err := toTime(vp.Get(key))
+		return errors.Wrapf(err, "Invalid --%s", key)
+	})
+	return func() time.Time {
+		t, _ := toTime(fs.load().Get(key))
+		return t
+	}
+}
+
+func (fs *FlagSet) TimeRequired(key, usage string) func() time.Time {
+	fs.required(key)
+	return fs.Time(key, time.Time{}, usage)
+}
+
+// toTime parses RFC3339 strings, config files such as yaml may already
+// decode timestamps to time.Time.
+func toTime(i interface{}) (time.Time, error) {
+	switch t := i.(type) {
+	case time.Time:
+		return t, nil
+	case nil:
+		return time.Time{}, nil
+	}
+	s := cast.ToString(i)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Enum is a string flag which only accepts one of allowed. An empty value
+// is accepted, use EnumRequired to reject it.
+func (fs *FlagSet) Enum(key, defaultValue string, allowed []string, usage string) func() string {
+	usage = fmt.Sprintf("%s. Support %s", usage, strings.Join(allowed, ", "))
+	getter := fs.String(key, defaultValue, usage)
+	fs.addCheck(func(vp *viper.Viper) error {
+		if val := vp.GetString(key); val != "" && !superSlices.NewStringSet(allowed).Contains(val) {
+			return errors.Errorf("Invalid --%s: %q, expect one of %s", key, val, strings.Join(allowed, ", "))
+		}
+		return nil
+	})
+	return getter
 }
 
-func DurationRequired(key, usage string) func() time.Duration {
-	requiredKey = append(requiredKey, key)
-	allKeys = append(allKeys, key)
-	return Duration(key, 0, usage)
+func (fs *FlagSet) EnumRequired(key string, allowed []string, usage string) func() string {
+	fs.required(key)
+	return fs.Enum(key, "", allowed, usage)
 }
@@ -0,0 +1,476 @@
+package superFlags
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/superwhys/superGo/superLog"
+)
+
+func TestFlagSet(t *testing.T) {
+	fs := NewSet("test")
+	port := fs.Int("mysql.port", 3306, "mysql port")
+	host := fs.StringRequired("mysql.host", "mysql host")
+	mode := fs.Enum("mode", "dev", []string{"dev", "prod"}, "mode")
+	fs.IntRange("mysql.port", 1, 65535)
+
+	if err := fs.Parse([]string{"--mysql.host", "db", "--mode", "prod"}); err != nil {
+		t.Fatal(err)
+	}
+	if port() != 3306 || host() != "db" || mode() != "prod" {
+		t.Fatalf("got %d %q %q", port(), host(), mode())
+	}
+	if got := fs.Sub("mysql").String("host"); got != "db" {
+		t.Fatalf("Sub(mysql).String(host) = %q", got)
+	}
+
+	// A second set declaring the same keys does not see the first one.
+	other := NewSet("other")
+	otherPort := other.Int("mysql.port", 3306, "mysql port")
+	other.StringRequired("mysql.host", "mysql host")
+	other.IntRange("mysql.port", 1, 65535)
+	err := other.Parse([]string{"--mysql.port", "0"})
+	if err == nil {
+		t.Fatal("expect validation errors")
+	}
+	for _, want := range []string{"Missing --mysql.host", "Invalid --mysql.port: 0"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not report %q", err, want)
+		}
+	}
+	if otherPort() != 0 || port() != 3306 {
+		t.Fatalf("sets are not isolated: %d %d", otherPort(), port())
+	}
+}
+
+func TestFlagSetConfigFile(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	content := "mysql:\n  host: fromfile\n  user: root\n"
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewSet("test")
+	host := fs.StringRequired("mysql.host", "mysql host")
+	err := fs.Parse([]string{"-f", config})
+	if err == nil || !strings.Contains(err.Error(), "Unknown flag in config: --mysql.user") {
+		t.Fatalf("expect the unknown nested key to be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), "Missing") {
+		t.Fatalf("required key set in the config file reported missing: %v", err)
+	}
+
+	fs = NewSet("test")
+	host = fs.StringRequired("mysql.host", "mysql host")
+	fs.String("mysql.user", "", "mysql user")
+	if err := fs.Parse([]string{"-f", config}); err != nil {
+		t.Fatal(err)
+	}
+	if host() != "fromfile" {
+		t.Fatalf("host = %q", host())
+	}
+
+	malformed := filepath.Join(t.TempDir(), "malformed.yaml")
+	if err := os.WriteFile(malformed, []byte("mysql: [host\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{filepath.Join(t.TempDir(), "missing.yaml"), malformed} {
+		if err := NewSet("test").Parse([]string{"-f", file}); err == nil {
+			t.Errorf("expect an error reading %s", file)
+		}
+	}
+}
+
+func TestFlagSetDump(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, []byte("mysql:\n  password: hunter2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewSet("test")
+	fs.String("mysql.host", "localhost", "mysql host")
+	fs.String("mysql.password", "", "mysql password")
+	fs.Int("mysql.port", 3306, "mysql port")
+	fs.MarkSecret("mysql.password")
+	if err := fs.Parse([]string{"-f", config, "--mysql.port", "3307"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var b strings.Builder
+	if err := fs.Dump(&b, "json"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"key": "mysql.host",
+    "value": "localhost",
+    "source": "default"`,
+		`"key": "mysql.password",
+    "value": "***",
+    "source": "file"`,
+		`"key": "mysql.port",
+    "value": 3307,
+    "source": "flag"`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("dump does not contain %s:\n%s", want, b.String())
+		}
+	}
+	if strings.Contains(b.String(), "hunter2") {
+		t.Errorf("dump leaks the secret:\n%s", b.String())
+	}
+}
+
+func TestSecret(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "mysql-password")
+	if err := os.WriteFile(file, []byte("from-file-1\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_REDIS_PASSWORD", "from-env")
+
+	fs := NewSet("test")
+	mysql := fs.Secret("mysql.password", "mysql password")
+	redis := fs.Secret("redis.password", "redis password")
+	mongo := fs.Secret("mongo.password", "mongo password")
+	defer fs.Close()
+	err := fs.Parse([]string{
+		"--mysql.password", "file:" + file,
+		"--redis.password", "env:TEST_REDIS_PASSWORD",
+		"--mongo.password", "literal-secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mysql() != "from-file-1" || redis() != "from-env" || mongo() != "literal-secret" {
+		t.Fatalf("got %q %q %q", mysql(), redis(), mongo())
+	}
+	if got := superLog.Redact("connect with literal-secret"); got != "connect with "+superLog.RedactedValue {
+		t.Errorf("secret not redacted from logs: %q", got)
+	}
+
+	var b strings.Builder
+	if err := fs.Dump(&b, "yaml"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(b.String(), "literal-secret") || strings.Contains(b.String(), "from-env") {
+		t.Errorf("dump leaks a secret:\n%s", b.String())
+	}
+
+	if err := os.WriteFile(file, []byte("from-file-2\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for mysql() != "from-file-2" {
+		if time.Now().After(deadline) {
+			t.Fatalf("secret file not reloaded, got %q", mysql())
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	unset := NewSet("test")
+	unset.Secret("password", "password")
+	if err := unset.Parse([]string{"--password", "env:TEST_UNSET_PASSWORD"}); err == nil {
+		t.Fatal("expect an error for an unset environment variable")
+	}
+
+	// A required secret resolving to an empty value is missing.
+	t.Setenv("TEST_EMPTY_PASSWORD", "")
+	emptyFile := filepath.Join(dir, "empty")
+	if err := os.WriteFile(emptyFile, []byte("\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, ref := range []string{"env:TEST_EMPTY_PASSWORD", "file:" + emptyFile} {
+		empty := NewSet("test")
+		empty.SecretRequired("password", "password")
+		err := empty.Parse([]string{"--password", ref})
+		if err == nil || !strings.Contains(err.Error(), "Missing --password") {
+			t.Errorf("%s: expect the password reported missing, got %v", ref, err)
+		}
+	}
+
+	// Bind honors the default of a secret field.
+	var cfg struct {
+		Password string `flag:"password" secret:"true" default:"env:TEST_REDIS_PASSWORD"`
+	}
+	bound := NewSet("test")
+	bound.Bind(&cfg)
+	if err := bound.Parse(nil); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Password != "from-env" {
+		t.Errorf("bound password = %q, want the default env reference resolved", cfg.Password)
+	}
+}
+
+// changes records the OnChange calls of a key.
+type changes struct {
+	lock  sync.Mutex
+	calls [][2]interface{}
+}
+
+func (c *changes) add(old, new interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.calls = append(c.calls, [2]interface{}{old, new})
+}
+
+func (c *changes) get() [][2]interface{} {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([][2]interface{}(nil), c.calls...)
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestConfigReload(t *testing.T) {
+	rec := superLog.Record(t)
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(config, []byte("port: 8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewSet("test")
+	port := fs.Int("port", 80, "port")
+	fs.IntRange("port", 1, 65535)
+	var c changes
+	fs.OnChange("port", c.add)
+	if err := fs.Parse([]string{"-f", config, "--config-watch"}); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+
+	if err := os.WriteFile(config, []byte("port: 9090\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the reload", func() bool { return port() == 9090 })
+	if calls := c.get(); len(calls) != 1 || calls[0] != [2]interface{}{8080, 9090} {
+		t.Fatalf("OnChange calls = %v", calls)
+	}
+
+	if err := os.WriteFile(config, []byte("port: 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the rejected reload", func() bool {
+		return rec.Contains(superLog.ErrorLevel, "Reject config reload")
+	})
+	if port() != 9090 {
+		t.Fatalf("invalid reload applied, port = %d", port())
+	}
+	if calls := c.get(); len(calls) != 1 {
+		t.Fatalf("OnChange called for an invalid reload: %v", calls)
+	}
+
+	fs.Close()
+	if err := os.WriteFile(config, []byte("port: 7070\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(3 * reloadDelay)
+	if port() != 9090 {
+		t.Fatalf("reloaded after Close, port = %d", port())
+	}
+}
+
+// TestConfigReloadSymlink swaps the config the way kubernetes updates a
+// mounted ConfigMap: config.yaml -> ..data/config.yaml, ..data -> v1.
+func TestConfigReloadSymlink(t *testing.T) {
+	dir := t.TempDir()
+	for version, content := range map[string]string{"v1": "port: 8080\n", "v2": "port: 9090\n"} {
+		if err := os.Mkdir(filepath.Join(dir, version), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, version, "config.yaml"), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := filepath.Join(dir, "..data")
+	config := filepath.Join(dir, "config.yaml")
+	if err := os.Symlink("v1", data); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join("..data", "config.yaml"), config); err != nil {
+		t.Fatal(err)
+	}
+
+	fs := NewSet("test")
+	port := fs.Int("port", 80, "port")
+	var c changes
+	fs.OnChange("port", c.add)
+	if err := fs.Parse([]string{"-f", config, "--config-watch"}); err != nil {
+		t.Fatal(err)
+	}
+	defer fs.Close()
+	if port() != 8080 {
+		t.Fatalf("port = %d", port())
+	}
+
+	tmp := filepath.Join(dir, "..data_tmp")
+	if err := os.Symlink("v2", tmp); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, data); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "the reload", func() bool { return port() == 9090 })
+	if calls := c.get(); len(calls) != 1 || calls[0] != [2]interface{}{8080, 9090} {
+		t.Fatalf("OnChange calls = %v", calls)
+	}
+}
+
+type BindCommon struct {
+	Verbose bool `default:"true"`
+}
+
+type bindConfig struct {
+	BindCommon
+	Name    string            `required:"true"`
+	Mode    string            `enum:"dev,prod" default:"dev"`
+	Token   string            `secret:"true"`
+	Offset  int64             `default:"-5"`
+	Workers uint              `default:"4"`
+	Ratio   float64           `default:"0.5"`
+	Timeout time.Duration     `default:"3s"`
+	Start   time.Time         `default:"2024-01-02T03:04:05Z"`
+	Tags    []string          `default:"a,b"`
+	Ports   []int             `default:"80,443"`
+	Backoff []time.Duration   `default:"1s,2s"`
+	Labels  map[string]string `default:"team=infra,env=dev"`
+	Skipped string            `flag:"-"`
+	MySQL   struct {
+		Host string `flag:"host" default:"localhost"`
+		Port int    `flag:"port" default:"3306"`
+	} `flag:"mysql"`
+}
+
+func TestBind(t *testing.T) {
+	var cfg bindConfig
+	fs := NewSet("test")
+	fs.Bind(&cfg)
+	err := fs.Parse([]string{"--name", "svc", "--mysql.host", "db", "--token", "bind-token", "--verbose=false"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start, _ := time.Parse(time.RFC3339, "2024-01-02T03:04:05Z")
+	want := bindConfig{
+		Name:    "svc",
+		Mode:    "dev",
+		Token:   "bind-token",
+		Offset:  -5,
+		Workers: 4,
+		Ratio:   0.5,
+		Timeout: 3 * time.Second,
+		Start:   start,
+		Tags:    []string{"a", "b"},
+		Ports:   []int{80, 443},
+		Backoff: []time.Duration{time.Second, 2 * time.Second},
+		Labels:  map[string]string{"team": "infra", "env": "dev"},
+	}
+	want.MySQL.Host = "db"
+	want.MySQL.Port = 3306
+	if !reflect.DeepEqual(cfg, want) {
+		t.Fatalf("got  %+v\nwant %+v", cfg, want)
+	}
+	if fs.flags.Lookup("skipped") != nil {
+		t.Error("flag:\"-\" field registered")
+	}
+	for _, v := range fs.Values() {
+		if v.Key == "token" && v.Value != superLog.RedactedValue {
+			t.Errorf("secret field not masked: %v", v.Value)
+		}
+	}
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{nil, "Missing --name"},
+		{[]string{"--name", "svc", "--mode", "test"}, "Invalid --mode"},
+	} {
+		var cfg bindConfig
+		fs := NewSet("test")
+		fs.Bind(&cfg)
+		if err := fs.Parse(tc.args); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) = %v, want %q", tc.args, err, tc.want)
+		}
+	}
+}
+
+func TestEnvName(t *testing.T) {
+	fs := NewSet("test")
+	prefixed := NewSet("test")
+	prefixed.EnableEnv("myapp_")
+	for _, tc := range []struct {
+		fs   *FlagSet
+		key  string
+		want string
+	}{
+		{fs, "serviceName", "SERVICE_NAME"},
+		{fs, "mysql.host", "MYSQL_HOST"},
+		{fs, "log-level", "LOG_LEVEL"},
+		{fs, "HTTPPort", "HTTP_PORT"},
+		{fs, "redis.poolSize2", "REDIS_POOL_SIZE2"},
+		{prefixed, "mysql.host", "MYAPP_MYSQL_HOST"},
+	} {
+		if got := tc.fs.EnvName(tc.key); got != tc.want {
+			t.Errorf("EnvName(%q) = %q, want %q", tc.key, got, tc.want)
+		}
+	}
+}
+
+func TestEnvPrecedence(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	content := "mysql:\n  host: fromfile\n  user: fromfile\n  db: fromfile\n"
+	if err := os.WriteFile(config, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TEST_MYSQL_HOST", "fromenv")
+	t.Setenv("TEST_MYSQL_USER", "fromenv")
+	t.Setenv("TEST_MYSQL_TAGS", "a, b")
+
+	fs := NewSet("test")
+	fs.EnableEnv("test")
+	host := fs.String("mysql.host", "default", "mysql host")
+	user := fs.String("mysql.user", "default", "mysql user")
+	db := fs.String("mysql.db", "default", "mysql db")
+	port := fs.Int("mysql.port", 3306, "mysql port")
+	tags := fs.Slice("mysql.tags", nil, "mysql tags")
+	if err := fs.Parse([]string{"-f", config, "--mysql.host", "fromflag"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if host() != "fromflag" || user() != "fromenv" || db() != "fromfile" || port() != 3306 {
+		t.Fatalf("got %q %q %q %d", host(), user(), db(), port())
+	}
+	if got := tags(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("tags = %q, want the env list split", got)
+	}
+
+	sources := map[string]string{}
+	for _, v := range fs.Values() {
+		sources[v.Key] = v.Source
+	}
+	for key, want := range map[string]string{
+		"mysql.host": SourceFlag,
+		"mysql.user": SourceEnv,
+		"mysql.db":   SourceFile,
+		"mysql.port": SourceDefault,
+	} {
+		if sources[key] != want {
+			t.Errorf("source of %s = %q, want %q", key, sources[key], want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package superFlags
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+
+	lg "github.com/superwhys/superGo/superLog"
+)
+
+var (
+	logFormat        func() string
+	logLevel         func() string
+	logVModule       func() string
+	logStdout        func() bool
+	logFile          func() string
+	logFileLevel     func() string
+	logFileMaxSize   func() int
+	logFileMaxBackup func() int
+	logFileMaxAge    func() int
+	logSyslog        func() string
+	logSyslogLevel   func() string
+	logAsyncBuffer   func() int
+	logAsyncOverflow func() string
+	logErrorStack    func() bool
+	logRedact        func() bool
+	logRedactKeys    func() []string
+)
+
+func initLogFlags() {
+	logFormat = String("log-format", lg.FormatConsole, "Set the log output format. Support console, text and json")
+	logLevel = String("log-level", lg.InfoLevel.String(), "Set the minimum log level. Support trace, debug, info, warn, error and fatal")
+	logVModule = String("log-vmodule", "", "Override the log level per package, e.g. superMongo=debug,superKafka=warn,myservice/*=info")
+	logStdout = Bool("log-stdout", true, "Write logs to stdout and stderr")
+	logFile = String("log-file", "", "Also write logs to this file, rotated by size")
+	logFileLevel = String("log-file-level", lg.TraceLevel.String(), "The minimum level written to --log-file")
+	logFileMaxSize = Int("log-file-max-size", 100, "Rotate --log-file once it reaches this size in megabytes")
+	logFileMaxBackup = Int("log-file-max-backup", 10, "The number of rotated log files to keep, 0 keeps all")
+	logFileMaxAge = Int("log-file-max-age", 30, "The number of days to keep rotated log files, 0 keeps all")
+	logSyslog = String("log-syslog", "", "Also write logs to syslog. Use 'local' for the local socket or 'udp://host:514'")
+	logSyslogLevel = String("log-syslog-level", lg.TraceLevel.String(), "The minimum level written to --log-syslog")
+	logAsyncBuffer = Int("log-async-buffer", 0, "Write logs asynchronously through a buffer of this many entries, 0 writes synchronously")
+	logAsyncOverflow = String("log-async-overflow", lg.Block.String(), "What to do when the async log buffer is full. Support block, drop-oldest and drop-newest")
+	logErrorStack = Bool("log-error-stack", false, "Attach the caller stack to every error log without an error stack")
+	logRedact = Bool("log-redact", true, "Mask passwords and tokens in log output")
+	logRedactKeys = Slice("log-redact-keys", nil, "Extra field names whose values are masked in log output")
+}
+
+// setupLog applies the log format and level. --debug wins over --log-level.
+func setupLog(debug bool) {
+	if err := lg.SetFormat(logFormat()); err != nil {
+		lg.Fatal(err)
+	}
+	lg.SetLevel(parseLevel(logLevel()))
+	if err := lg.SetVModule(logVModule()); err != nil {
+		lg.Fatal(err)
+	}
+	lg.EnableErrorStack(logErrorStack())
+	lg.SetRedaction(logRedact())
+	lg.AddSensitiveKeys(logRedactKeys()...)
+	if debug {
+		lg.EnableDebug()
+	}
+}
+
+// watchLogFlags applies the level and vmodule of a reloaded config file.
+// --debug still wins over --log-level.
+func watchLogFlags() {
+	OnChange("log-level", func(_, _ interface{}) {
+		lvl, err := lg.ParseLevel(logLevel())
+		if err != nil {
+			lg.Errorf("Ignore reloaded --log-level: %v", err)
+			return
+		}
+		if !*debug {
+			lg.SetLevel(lvl)
+		}
+	})
+	OnChange("log-vmodule", func(_, _ interface{}) {
+		if err := lg.SetVModule(logVModule()); err != nil {
+			lg.Errorf("Ignore reloaded --log-vmodule: %v", err)
+		}
+	})
+}
+
+// setupLogAsync enables async logging when --log-async-buffer is set.
+func setupLogAsync() {
+	if logAsyncBuffer() <= 0 {
+		return
+	}
+	policy, err := lg.ParseOverflowPolicy(logAsyncOverflow())
+	if err != nil {
+		lg.Fatal(err)
+	}
+	lg.EnableAsync(logAsyncBuffer(), policy)
+}
+
+// setupLogSinks replaces the default console sink with the configured ones.
+func setupLogSinks() {
+	if logStdout() && logFile() == "" && logSyslog() == "" {
+		return
+	}
+
+	var sinks []lg.Sink
+	if logStdout() {
+		sinks = append(sinks, lg.NewConsoleSink())
+	}
+	if logFile() != "" {
+		sinks = append(sinks, lg.NewFileSink(
+			logFile(),
+			logFileMaxSize(),
+			logFileMaxBackup(),
+			logFileMaxAge(),
+			lg.WithMinLevel(parseLevel(logFileLevel())),
+		))
+	}
+	if logSyslog() != "" {
+		var network, addr string
+		if logSyslog() != "local" {
+			u, err := url.Parse(logSyslog())
+			if err != nil {
+				lg.Fatalf("Invalid --log-syslog: %v", err)
+			}
+			network, addr = u.Scheme, u.Host
+		}
+		sink, err := lg.NewSyslogSink(network, addr, filepath.Base(os.Args[0]), lg.WithMinLevel(parseLevel(logSyslogLevel())))
+		if err != nil {
+			lg.Fatal(err)
+		}
+		sinks = append(sinks, sink)
+	}
+	lg.SetSinks(sinks...)
+}
+
+func parseLevel(name string) lg.Level {
+	lvl, err := lg.ParseLevel(name)
+	if err != nil {
+		lg.Fatal(err)
+	}
+	return lvl
+}
@@ -0,0 +1,175 @@
+package superFlags
+
+import (
+	"path/filepath"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	lg "github.com/superwhys/superGo/superLog"
+)
+
+// reloadDelay coalesces the several events editors emit for a single save.
+const reloadDelay = 100 * time.Millisecond
+
+type subscription struct {
+	key string
+	fn  func(old, new interface{})
+}
+
+// load returns the viper the getters read from.
+func (fs *FlagSet) load() *viper.Viper {
+	if vp, ok := fs.current.Load().(*viper.Viper); ok {
+		return vp
+	}
+	return fs.v
+}
+
+// OnChange calls fn with the previous and the new value of key every time
+// a reload of the config file changes it. key may also be a section such as
+// "mysql". The getters always return the current values, while the structs
+// filled by Bind are not updated on reload.
+func (fs *FlagSet) OnChange(key string, fn func(old, new interface{})) {
+	fs.subsMu.Lock()
+	defer fs.subsMu.Unlock()
+	fs.subs = append(fs.subs, subscription{key: key, fn: fn})
+}
+
+// watchConfig reloads the config file whenever it changes.
+func (fs *FlagSet) watchConfig(filename string) {
+	if err := fs.watch(filename, fs.reload); err != nil {
+		lg.Errorf("Failed to watch config file: %v", err)
+	}
+}
+
+// watch calls onChange whenever filename changes, until fs.Close.
+func (fs *FlagSet) watch(filename string, onChange func()) error {
+	stop, err := watchFile(filename, onChange)
+	if err != nil {
+		return err
+	}
+	fs.watchMu.Lock()
+	defer fs.watchMu.Unlock()
+	fs.watchers = append(fs.watchers, stop)
+	return nil
+}
+
+// Close stops watching the config file and the secret files of fs. The
+// getters keep returning the last values read.
+func (fs *FlagSet) Close() {
+	fs.watchMu.Lock()
+	watchers := fs.watchers
+	fs.watchers = nil
+	fs.watchMu.Unlock()
+	for _, stop := range watchers {
+		stop()
+	}
+}
+
+// watchFile calls onChange once filename has changed, until stop is
+// called. The directory is watched rather than the file, editors and
+// kubernetes replace the file.
+func watchFile(filename string, onChange func()) (stop func(), err error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, errors.Wrap(err, "Create watcher")
+	}
+	filename = filepath.Clean(filename)
+	if err := watcher.Add(filepath.Dir(filename)); err != nil {
+		watcher.Close()
+		return nil, errors.Wrapf(err, "Watch %s", filename)
+	}
+
+	// Resolved before returning, a swap right after must count as a change.
+	realFile, _ := filepath.EvalSymlinks(filename)
+	go func() {
+		var timer *time.Timer
+		defer func() {
+			if timer != nil {
+				timer.Stop()
+			}
+		}()
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				// A changed symlink target is how kubernetes updates a ConfigMap.
+				target, _ := filepath.EvalSymlinks(filename)
+				changed := filepath.Clean(event.Name) == filename && event.Op&(fsnotify.Write|fsnotify.Create) != 0
+				if !changed && (target == "" || target == realFile) {
+					continue
+				}
+				realFile = target
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDelay, onChange)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				lg.Errorf("Watch %s: %v", filename, err)
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() { watcher.Close() })
+	}, nil
+}
+
+// reload reads the config file into a new viper and swaps it in once it is
+// valid. Invalid configs are logged and ignored.
+func (fs *FlagSet) reload() {
+	fs.reloadMu.Lock()
+	defer fs.reloadMu.Unlock()
+
+	next, err := fs.rebuild()
+	if err != nil {
+		lg.Errorf("Reject config reload from %s: %v", *fs.config, err)
+		return
+	}
+	old := fs.load()
+	fs.current.Store(next)
+	lg.Infof("Reload config from local file: %v!", *fs.config)
+
+	fs.subsMu.Lock()
+	snapshot := append([]subscription(nil), fs.subs...)
+	fs.subsMu.Unlock()
+	for _, sub := range snapshot {
+		oldValue, newValue := old.Get(sub.key), next.Get(sub.key)
+		if !reflect.DeepEqual(oldValue, newValue) {
+			sub.fn(oldValue, newValue)
+		}
+	}
+}
+
+// rebuild returns a new viper with the same flags, env and defaults as the
+// current one, and the config file read again.
+func (fs *FlagSet) rebuild() (*viper.Viper, error) {
+	next := viper.New()
+	for _, key := range fs.allKeys {
+		if def, ok := fs.defaults[key]; ok {
+			next.SetDefault(key, def)
+		}
+		if flag := fs.flags.Lookup(key); flag != nil {
+			if err := next.BindPFlag(key, flag); err != nil {
+				return nil, errors.Wrapf(err, "BindPFlag err, Key: --%s", key)
+			}
+		}
+	}
+	fs.bindEnv(next)
+	next.SetConfigFile(*fs.config)
+	if err := next.ReadInConfig(); err != nil {
+		return nil, errors.Wrap(err, "Read config")
+	}
+	if err := fs.validate(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
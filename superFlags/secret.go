@@ -0,0 +1,153 @@
+package superFlags
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	lg "github.com/superwhys/superGo/superLog"
+)
+
+const (
+	secretFilePrefix = "file:"
+	secretEnvPrefix  = "env:"
+	// minRedactLength avoids masking every "a" of the logs for a short secret.
+	minRedactLength = 4
+)
+
+// secret caches the resolved value of a Secret flag.
+type secret struct {
+	fs      *FlagSet
+	lock    sync.Mutex
+	ref     string
+	value   string
+	stale   bool
+	watched map[string]bool
+}
+
+// Secret is a string flag for passwords and tokens. The value is either
+// the secret itself, `file:/path` to read it from a file such as a mounted
+// kubernetes secret, or `env:NAME` to read it from an environment variable.
+//
+// The value is masked in Dump and in superLog output, and a referenced
+// file is read again when it changes.
+func (fs *FlagSet) Secret(key, usage string) func() string {
+	return fs.secret(key, "", usage)
+}
+
+func (fs *FlagSet) SecretRequired(key, usage string) func() string {
+	fs.required(key)
+	return fs.Secret(key, usage)
+}
+
+// secret declares a Secret flag with a default, such as `env:DB_PASS`.
+func (fs *FlagSet) secret(key, defaultValue, usage string) func() string {
+	fs.flags.String(key, defaultValue, usage)
+	fs.register(key, defaultValue)
+	fs.MarkSecret(key)
+	fs.addCheck(func(vp *viper.Viper) error {
+		ref := vp.GetString(key)
+		value, err := resolveSecret(ref)
+		if err != nil {
+			return errors.Wrapf(err, "Invalid --%s", key)
+		}
+		// An empty reference is reported by the required check itself.
+		if value == "" && ref != "" && fs.isRequired(key) {
+			return errors.Errorf("Missing --%s: %s is empty", key, ref)
+		}
+		// Mask it before anything gets a chance to log it.
+		redactSecret(value)
+		return nil
+	})
+	s := &secret{fs: fs, watched: map[string]bool{}}
+	return func() string {
+		return s.get(fs.load().GetString(key))
+	}
+}
+
+func (fs *FlagSet) isRequired(key string) bool {
+	for _, k := range fs.requiredKey {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// get returns the value of ref, resolved again when ref or the file it
+// references changed.
+func (s *secret) get(ref string) string {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if ref == s.ref && !s.stale {
+		return s.value
+	}
+
+	value, err := resolveSecret(ref)
+	if err != nil {
+		// The error only holds the reference, never the secret.
+		lg.Errorf("Failed to read secret: %v", err)
+		return s.value
+	}
+	s.ref, s.value, s.stale = ref, value, false
+	redactSecret(value)
+	if path := strings.TrimPrefix(ref, secretFilePrefix); path != ref && !s.watched[path] {
+		s.watched[path] = true
+		if err := s.fs.watch(path, s.invalidate); err != nil {
+			lg.Errorf("Failed to watch secret file: %v", err)
+		}
+	}
+	return s.value
+}
+
+func (s *secret) invalidate() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.stale = true
+}
+
+// redacted holds the secrets already masked in superLog output.
+var redacted sync.Map
+
+// redactSecret masks value in superLog output.
+func redactSecret(value string) {
+	if len(value) < minRedactLength {
+		return
+	}
+	if _, loaded := redacted.LoadOrStore(value, true); loaded {
+		return
+	}
+	if err := lg.AddRedactPattern(regexp.QuoteMeta(value), lg.RedactedValue); err != nil {
+		lg.Errorf("Failed to redact secret: %v", err)
+	}
+}
+
+// isSecretRef reports whether value references a secret rather than being one.
+func isSecretRef(value string) bool {
+	return strings.HasPrefix(value, secretFilePrefix) || strings.HasPrefix(value, secretEnvPrefix)
+}
+
+// resolveSecret reads a `file:` or `env:` reference, other values are the
+// secret itself. The trailing newline of a file is dropped.
+func resolveSecret(ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, secretFilePrefix):
+		path := strings.TrimPrefix(ref, secretFilePrefix)
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", errors.Wrapf(err, "Read secret file %s", path)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	case strings.HasPrefix(ref, secretEnvPrefix):
+		name := strings.TrimPrefix(ref, secretEnvPrefix)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", name)
+		}
+		return value, nil
+	}
+	return ref, nil
+}
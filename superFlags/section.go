@@ -0,0 +1,97 @@
+package superFlags
+
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// Section is a view of the keys below a dotted prefix, e.g. Sub("mysql")
+// reads mysql.host from --mysql.host or from the mysql section of the
+// config file. Values are read on every call, after Parse.
+type Section struct {
+	fs     *FlagSet
+	prefix string
+}
+
+// Sub returns the view of the section name, which may itself be dotted.
+func (fs *FlagSet) Sub(name string) *Section {
+	return &Section{fs: fs, prefix: strings.Trim(name, ".") + "."}
+}
+
+// Sub returns the view of a section nested in s.
+func (s *Section) Sub(name string) *Section {
+	return &Section{fs: s.fs, prefix: s.prefix + strings.Trim(name, ".") + "."}
+}
+
+// Key returns the full key of key in s.
+func (s *Section) Key(key string) string {
+	return s.prefix + key
+}
+
+// Keys returns the declared keys of s, relative to s.
+func (s *Section) Keys() []string {
+	var keys []string
+	for _, k := range s.fs.allKeys {
+		if strings.HasPrefix(strings.ToLower(k), strings.ToLower(s.prefix)) {
+			keys = append(keys, k[len(s.prefix):])
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (s *Section) IsSet(key string) bool {
+	return s.fs.load().IsSet(s.Key(key))
+}
+
+func (s *Section) String(key string) string {
+	return s.fs.load().GetString(s.Key(key))
+}
+
+func (s *Section) Bool(key string) bool {
+	return s.fs.load().GetBool(s.Key(key))
+}
+
+func (s *Section) Int(key string) int {
+	return s.fs.load().GetInt(s.Key(key))
+}
+
+func (s *Section) Int64(key string) int64 {
+	return s.fs.load().GetInt64(s.Key(key))
+}
+
+func (s *Section) Uint(key string) uint {
+	return s.fs.load().GetUint(s.Key(key))
+}
+
+func (s *Section) Float64(key string) float64 {
+	return s.fs.load().GetFloat64(s.Key(key))
+}
+
+func (s *Section) Duration(key string) time.Duration {
+	return s.fs.load().GetDuration(s.Key(key))
+}
+
+func (s *Section) Slice(key string) []string {
+	return toStringSlice(s.fs.load().Get(s.Key(key)))
+}
+
+func (s *Section) IntSlice(key string) []int {
+	n, _ := toIntSlice(s.fs.load().Get(s.Key(key)))
+	return n
+}
+
+func (s *Section) DurationSlice(key string) []time.Duration {
+	d, _ := toDurationSlice(s.fs.load().Get(s.Key(key)))
+	return d
+}
+
+func (s *Section) StringMap(key string) map[string]string {
+	return toStringMap(s.fs.load().Get(s.Key(key)))
+}
+
+func (s *Section) Time(key string) time.Time {
+	t, _ := toTime(s.fs.load().Get(s.Key(key)))
+	return t
+}
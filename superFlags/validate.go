@@ -0,0 +1,123 @@
+package superFlags
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/viper"
+	lg "github.com/superwhys/superGo/superLog"
+	"github.com/superwhys/superGo/superSlices"
+)
+
+func (fs *FlagSet) addCheck(check func(vp *viper.Viper) error) {
+	fs.checks = append(fs.checks, check)
+}
+
+// IntRange rejects values of key outside [min, max].
+func (fs *FlagSet) IntRange(key string, min, max int) {
+	fs.addCheck(func(vp *viper.Viper) error {
+		if n := vp.GetInt(key); n < min || n > max {
+			return errors.Errorf("Invalid --%s: %d, expect between %d and %d", key, n, min, max)
+		}
+		return nil
+	})
+}
+
+// Regex rejects values of key not matching expr. Empty values are left to
+// the Required variants.
+func (fs *FlagSet) Regex(key, expr string) {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		lg.Fatalf("Invalid regex of --%s: %v", key, err)
+	}
+	fs.addCheck(func(vp *viper.Viper) error {
+		if val := vp.GetString(key); val != "" && !re.MatchString(val) {
+			return errors.Errorf("Invalid --%s: %q, expect to match %s", key, val, expr)
+		}
+		return nil
+	})
+}
+
+// Validate rejects the value of key when fn returns an error.
+func (fs *FlagSet) Validate(key string, fn func(value interface{}) error) {
+	fs.addCheck(func(vp *viper.Viper) error {
+		return errors.Wrapf(fn(vp.Get(key)), "Invalid --%s", key)
+	})
+}
+
+// ValidationError lists every problem found in the merged configuration.
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("Invalid configuration:\n\t%s", strings.Join(e.Problems, "\n\t"))
+}
+
+// validate checks the merged flags, env and config file values: unknown
+// keys, missing required keys and the validators. It reports every problem
+// at once.
+func (fs *FlagSet) validate(vp *viper.Viper) error {
+	if problems := fs.problems(vp); len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+func (fs *FlagSet) problems(vp *viper.Viper) []string {
+	var problems []string
+	// The config file of a subcommand is checked by its parent, which
+	// knows the keys of every subcommand.
+	if fs.parent == nil {
+		for _, k := range fs.unknownKeys(vp) {
+			problems = append(problems, fmt.Sprintf("Unknown flag in config: --%s", k))
+		}
+	}
+	for _, k := range fs.requiredKey {
+		if isZero(vp.Get(k)) {
+			problems = append(problems, fmt.Sprintf("Missing --%s", k))
+		}
+	}
+	for _, check := range fs.checks {
+		if err := check(vp); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// unknownKeys returns the config keys which are not declared, nested ones
+// included. Any key is allowed below a StringMap.
+func (fs *FlagSet) unknownKeys(vp *viper.Viper) []string {
+	expectedKeys := superSlices.NewStringSet(nil)
+	for _, set := range fs.related() {
+		for _, k := range set.allKeys {
+			if err := expectedKeys.Add(strings.ToLower(k)); err != nil {
+				lg.Fatalf("Add Key Error: --%s", k)
+			}
+		}
+	}
+
+	var unknown []string
+	for _, k := range vp.AllKeys() {
+		if !expectedKeys.Contains(k) && !fs.underMapKey(k) {
+			unknown = append(unknown, k)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
+func (fs *FlagSet) underMapKey(k string) bool {
+	for _, set := range fs.related() {
+		for _, m := range set.mapKeys {
+			if strings.HasPrefix(k, strings.ToLower(m)+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
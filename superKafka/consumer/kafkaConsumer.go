@@ -75,12 +75,12 @@ func (sr *SuperKafkaReader) ReadMessageWithProto(protoMsg proto.Message) (*kafka
 			return nil, errors.New("Reader closed")
 		}
 		if err != nil {
-			superLog.Error("Read kafka", err)
+			superLog.Every(time.Second).Error("Read kafka", err)
 			time.Sleep(bf.NextBackOff())
 			continue
 		}
 		if err := proto.Unmarshal(msg.Value, protoMsg); err != nil {
-			superLog.Errorf("Decode kafka message. Partition=%d Offset=%d %s", msg.Partition, msg.Offset, err)
+			superLog.Every(time.Second).Errorf("Decode kafka message. Partition=%d Offset=%d %s", msg.Partition, msg.Offset, err)
 			continue
 		}
 		msg.Value = nil
@@ -97,12 +97,12 @@ func (sr *SuperKafkaReader) ReadMessageWithJson(out interface{}) (*kafka.Message
 			return nil, errors.New("Reader closed")
 		}
 		if err != nil {
-			superLog.Error("Read kafka", err)
+			superLog.Every(time.Second).Error("Read kafka", err)
 			time.Sleep(bf.NextBackOff())
 			continue
 		}
 		if err := codec.NewDecoderBytes(msg.Value, &codec.JsonHandle{}).Decode(out); err != nil {
-			superLog.Errorf("Decode kafka message. Partition=%d Offset=%d %s", msg.Partition, msg.Offset, err)
+			superLog.Every(time.Second).Errorf("Decode kafka message. Partition=%d Offset=%d %s", msg.Partition, msg.Offset, err)
 			continue
 		}
 		msg.Value = nil
@@ -5,6 +5,7 @@ import (
 	"context"
 	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
+	"github.com/segmentio/kafka-go"
 	"github.com/superwhys/superGo/superLog"
 	"github.com/ugorji/go/codec"
 	"math/rand"
@@ -108,6 +109,22 @@ func (sw *SuperKafkaWriter) WriteMessageWithProto(key string, msg proto.Message)
 	return writeMessage(sw.SuperWriter, []byte(key), data)
 }
 
+// WriteRawMessages writes already encoded values in a single batch, all with
+// the same key. Unlike the other Write methods it never logs nor retries
+// on its own, so it is safe to use from a superLog sink.
+func (sw *SuperKafkaWriter) WriteRawMessages(ctx context.Context, key string, values ...[]byte) error {
+	msgs := make([]kafka.Message, 0, len(values))
+	for _, value := range values {
+		msgs = append(msgs, kafka.Message{Key: []byte(key), Value: value})
+	}
+	return errors.Wrap(sw.SuperWriter.WriteMessages(ctx, msgs...), "Write kafka messages")
+}
+
+// Close flushes the pending messages and closes the writer.
+func (sw *SuperKafkaWriter) Close() error {
+	return sw.SuperWriter.Close()
+}
+
 func writeMessage(writer *kafka.Writer, key, value []byte) error {
 	if len(key) == 0 {
 		key = []byte(strconv.Itoa(rand.Int()))
@@ -0,0 +1,245 @@
+package superLog
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// OverflowPolicy decides what happens when the async buffer is full.
+type OverflowPolicy int
+
+const (
+	// Block waits for room in the buffer, as slow as synchronous logging.
+	Block OverflowPolicy = iota
+	// DropOldest discards the oldest buffered entry.
+	DropOldest
+	// DropNewest discards the entry being logged.
+	DropNewest
+)
+
+var overflowNames = map[OverflowPolicy]string{
+	Block:      "block",
+	DropOldest: "drop-oldest",
+	DropNewest: "drop-newest",
+}
+
+func (p OverflowPolicy) String() string {
+	if name, ok := overflowNames[p]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// ParseOverflowPolicy parses "block", "drop-oldest" or "drop-newest".
+func ParseOverflowPolicy(name string) (OverflowPolicy, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for p, n := range overflowNames {
+		if n == name {
+			return p, nil
+		}
+	}
+	return Block, errors.Errorf("unknown overflow policy: %q", name)
+}
+
+var (
+	asyncMu sync.RWMutex
+	queue   *asyncQueue
+)
+
+// EnableAsync makes logging calls return as soon as the entry is in a ring
+// buffer of size entries, a background goroutine writes them to the sinks.
+// Call Flush or Close before exiting, Fatal does it on its own.
+func EnableAsync(size int, policy OverflowPolicy) {
+	if size <= 0 {
+		size = 1
+	}
+	q := newAsyncQueue(size, policy)
+
+	asyncMu.Lock()
+	old := queue
+	queue = q
+	asyncMu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
+
+// DisableAsync drains the buffer and goes back to synchronous writes.
+func DisableAsync() {
+	asyncMu.Lock()
+	old := queue
+	queue = nil
+	asyncMu.Unlock()
+
+	if old != nil {
+		old.close()
+	}
+}
+
+// Flush blocks until every buffered entry is written and every hook has
+// fired, then syncs the sinks.
+func Flush() {
+	asyncMu.RLock()
+	q := queue
+	asyncMu.RUnlock()
+	if q != nil {
+		q.flush()
+	}
+	flushHooks()
+
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		if syncer, ok := s.(interface{ Sync() error }); ok {
+			if err := syncer.Sync(); err != nil {
+				fmt.Fprintln(os.Stderr, "superLog: sync sink:", err)
+			}
+		}
+	}
+}
+
+// Close drains the buffer, stops async mode and closes all the sinks.
+// Logging afterwards goes to a new console sink.
+func Close() {
+	DisableAsync()
+	Flush()
+	SetSinks(NewConsoleSink())
+}
+
+// write redacts and snapshots e on the caller's goroutine, fires the hooks
+// and hands e to the async buffer, or to the sinks when async is disabled.
+func write(e *Entry) {
+	e = redactEntry(e)
+	fireHooks(e)
+
+	asyncMu.RLock()
+	q := queue
+	asyncMu.RUnlock()
+	if q != nil && q.push(e) {
+		return
+	}
+	writeSinks(e)
+}
+
+// asyncQueue is a bounded ring buffer drained by a single goroutine.
+type asyncQueue struct {
+	lock     sync.Mutex
+	cond     *sync.Cond
+	buf      []*Entry
+	head     int
+	size     int
+	policy   OverflowPolicy
+	inFlight bool
+	closed   bool
+	dropped  int
+	done     chan struct{}
+}
+
+func newAsyncQueue(size int, policy OverflowPolicy) *asyncQueue {
+	q := &asyncQueue{
+		buf:    make([]*Entry, size),
+		policy: policy,
+		done:   make(chan struct{}),
+	}
+	q.cond = sync.NewCond(&q.lock)
+	go q.run()
+	return q
+}
+
+// push buffers e. It returns false once the queue is closed, the caller
+// then writes e synchronously.
+func (q *asyncQueue) push(e *Entry) bool {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	for q.size == len(q.buf) && q.policy == Block && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return false
+	}
+	if q.size == len(q.buf) {
+		q.dropped++
+		if q.policy == DropNewest {
+			return true
+		}
+		q.buf[q.head] = nil
+		q.head = (q.head + 1) % len(q.buf)
+		q.size--
+	}
+	q.buf[(q.head+q.size)%len(q.buf)] = e
+	q.size++
+	q.cond.Broadcast()
+	return true
+}
+
+func (q *asyncQueue) run() {
+	defer close(q.done)
+	for {
+		q.lock.Lock()
+		for q.size == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		dropped := q.dropped
+		q.dropped = 0
+		if q.size == 0 {
+			q.lock.Unlock()
+			reportOverflow(dropped, q.policy)
+			return
+		}
+		e := q.buf[q.head]
+		q.buf[q.head] = nil
+		q.head = (q.head + 1) % len(q.buf)
+		q.size--
+		q.inFlight = true
+		q.cond.Broadcast()
+		q.lock.Unlock()
+
+		reportOverflow(dropped, q.policy)
+		writeSinks(e)
+
+		q.lock.Lock()
+		q.inFlight = false
+		q.cond.Broadcast()
+		q.lock.Unlock()
+	}
+}
+
+func reportOverflow(dropped int, policy OverflowPolicy) {
+	if dropped == 0 {
+		return
+	}
+	writeSinks(&Entry{
+		Time:    time.Now(),
+		Level:   WarnLevel,
+		Message: "Dropped log entries, async buffer is full",
+		Fields: []Field{
+			{Key: "dropped", Value: dropped},
+			{Key: "policy", Value: policy.String()},
+		},
+	})
+}
+
+// flush waits until the buffer is empty and nothing is being written.
+func (q *asyncQueue) flush() {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	for q.size > 0 || q.inFlight {
+		q.cond.Wait()
+	}
+}
+
+// close writes what is left in the buffer and stops the goroutine.
+func (q *asyncQueue) close() {
+	q.lock.Lock()
+	q.closed = true
+	q.cond.Broadcast()
+	q.lock.Unlock()
+	<-q.done
+}
@@ -0,0 +1,100 @@
+package superLog
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// gateSink blocks on its first entry until release is closed, so that the
+// next entries pile up in the async buffer.
+type gateSink struct {
+	once    sync.Once
+	entered chan struct{}
+	release chan struct{}
+}
+
+func newGateSink() *gateSink {
+	return &gateSink{entered: make(chan struct{}), release: make(chan struct{})}
+}
+
+func (g *gateSink) Write(e *Entry) error {
+	g.once.Do(func() {
+		close(g.entered)
+		<-g.release
+	})
+	return nil
+}
+
+func (g *gateSink) Close() error {
+	return nil
+}
+
+func TestAsyncOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		policy OverflowPolicy
+		want   []string
+	}{
+		{Block, []string{"1", "2", "3", "4", "5"}},
+		{DropOldest, []string{"1", "Dropped log entries, async buffer is full", "4", "5"}},
+		{DropNewest, []string{"1", "Dropped log entries, async buffer is full", "2", "3"}},
+	} {
+		t.Run(tc.policy.String(), func(t *testing.T) {
+			r := Record(t)
+			gate := newGateSink()
+			AddSink(gate)
+			EnableAsync(2, tc.policy)
+			t.Cleanup(DisableAsync)
+
+			Info("1")
+			<-gate.entered
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 2; i <= 5; i++ {
+					Info(strconv.Itoa(i))
+				}
+			}()
+			if tc.policy != Block {
+				<-done
+			}
+			close(gate.release)
+			<-done
+			Flush()
+
+			if got := r.Messages(); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("messages = %q, want %q", got, tc.want)
+			}
+			if tc.policy != Block {
+				if v, _ := r.Level(WarnLevel)[0].Field("dropped"); v != 2 {
+					t.Errorf("dropped = %v, want 2", v)
+				}
+			}
+		})
+	}
+}
+
+func TestAsyncSnapshotsFields(t *testing.T) {
+	r := Record(t)
+	EnableAsync(1024, Block)
+	t.Cleanup(DisableAsync)
+
+	stats := map[string]int{"sent": 1}
+	ids := []int{1}
+	Infow("batch", "stats", stats, "ids", ids)
+	for i := 2; i < 100; i++ {
+		stats["sent"] = i
+		ids[0] = i
+	}
+	Flush()
+
+	e := r.Entries()[0]
+	if v, _ := e.Field("stats"); fmt.Sprint(v) != "map[sent:1]" {
+		t.Errorf("stats = %v, want the value at the time of the call", v)
+	}
+	if v, _ := e.Field("ids"); fmt.Sprint(v) != "[1]" {
+		t.Errorf("ids = %v, want the value at the time of the call", v)
+	}
+}
@@ -0,0 +1,159 @@
+package superLog
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type loggerCtxKey struct{}
+
+type contextKey string
+
+// Context keys used by WithRequestID, WithTraceID and WithUserID.
+const (
+	RequestIDKey contextKey = "request_id"
+	TraceIDKey   contextKey = "trace_id"
+	UserIDKey    contextKey = "user_id"
+)
+
+var (
+	ctxKeysMu sync.RWMutex
+	ctxKeys   = []ctxField{
+		{name: string(RequestIDKey), key: RequestIDKey},
+		{name: string(TraceIDKey), key: TraceIDKey},
+		{name: string(UserIDKey), key: UserIDKey},
+	}
+)
+
+type ctxField struct {
+	name string
+	key  interface{}
+}
+
+// RegisterContextKey makes every entry logged with a context carrying key
+// get a field called name with the value found in the context.
+// Use it for ids stored by other libraries under their own keys.
+func RegisterContextKey(name string, key interface{}) {
+	ctxKeysMu.Lock()
+	defer ctxKeysMu.Unlock()
+	for i, f := range ctxKeys {
+		if f.name == name {
+			ctxKeys[i].key = key
+			return
+		}
+	}
+	ctxKeys = append(ctxKeys, ctxField{name: name, key: key})
+}
+
+// WithContext returns a copy of ctx carrying a logger with the given
+// key-value pairs, on top of the logger already carried by ctx.
+func WithContext(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	return NewContext(ctx, loggerFromContext(ctx).With(keysAndValues...))
+}
+
+// NewContext returns a copy of ctx carrying l.
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerCtxKey{}, l)
+}
+
+// WithRequestID stores a request id in ctx, attached to all entries logged with ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, RequestIDKey, id)
+}
+
+// WithTraceID stores a trace id in ctx, attached to all entries logged with ctx.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, TraceIDKey, id)
+}
+
+// WithUserID stores a user id in ctx, attached to all entries logged with ctx.
+func WithUserID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, UserIDKey, id)
+}
+
+// RequestID returns the request id stored in ctx, if any.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// FromContext returns the logger carried by ctx, or the default logger,
+// with the registered context values attached as fields.
+func FromContext(ctx context.Context) *Logger {
+	l := loggerFromContext(ctx)
+	if ctx == nil {
+		return l
+	}
+
+	ctxKeysMu.RLock()
+	defer ctxKeysMu.RUnlock()
+	var kvs []interface{}
+	for _, f := range ctxKeys {
+		if val := ctx.Value(f.key); val != nil {
+			kvs = append(kvs, f.name, val)
+		}
+	}
+	if len(kvs) == 0 {
+		return l
+	}
+	return l.With(kvs...)
+}
+
+func loggerFromContext(ctx context.Context) *Logger {
+	if ctx == nil {
+		return std
+	}
+	if l, ok := ctx.Value(loggerCtxKey{}).(*Logger); ok && l != nil {
+		return l
+	}
+	return std
+}
+
+func TraceCtx(ctx context.Context, v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		FromContext(ctx).output(TraceLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func DebugCtx(ctx context.Context, v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		FromContext(ctx).output(DebugLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func InfoCtx(ctx context.Context, v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		FromContext(ctx).output(InfoLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func WarnCtx(ctx context.Context, v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		FromContext(ctx).output(WarnLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func ErrorCtx(ctx context.Context, v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		FromContext(ctx).output(ErrorLevel, fmt.Sprintln(v...), errorFields(v))
+	}
+}
+
+func InfofCtx(ctx context.Context, msg string, v ...interface{}) {
+	FromContext(ctx).output(InfoLevel, fmt.Sprintf(msg, v...), nil)
+}
+
+func ErrorfCtx(ctx context.Context, msg string, v ...interface{}) {
+	FromContext(ctx).output(ErrorLevel, fmt.Sprintf(msg, v...), errorFields(v))
+}
+
+// InfowCtx logs msg with the context fields and the given key-value pairs.
+func InfowCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	FromContext(ctx).output(InfoLevel, msg, toFields(keysAndValues))
+}
+
+// ErrorwCtx logs msg with the context fields and the given key-value pairs.
+func ErrorwCtx(ctx context.Context, msg string, keysAndValues ...interface{}) {
+	FromContext(ctx).output(ErrorLevel, msg, append(toFields(keysAndValues), errorFields(keysAndValues)...))
+}
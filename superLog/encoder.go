@@ -0,0 +1,188 @@
+package superLog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/pkg/errors"
+)
+
+// Entry is a single log line before it is encoded.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Caller  runtime.Frame
+	Message string
+	Fields  []Field
+}
+
+// Encoder turns an entry into the bytes written to the output, including
+// the trailing newline.
+type Encoder interface {
+	Encode(e *Entry) ([]byte, error)
+}
+
+// Names of the builtin output formats accepted by SetFormat.
+const (
+	FormatConsole = "console"
+	FormatText    = "text"
+	FormatJSON    = "json"
+)
+
+// NewEncoder returns the builtin encoder for the given format name.
+func NewEncoder(format string) (Encoder, error) {
+	switch strings.ToLower(format) {
+	case FormatConsole, "":
+		return &textEncoder{colored: true}, nil
+	case FormatText:
+		return &textEncoder{}, nil
+	case FormatJSON:
+		return &jsonEncoder{}, nil
+	default:
+		return nil, errors.Errorf("unknown log format: %s", format)
+	}
+}
+
+// textEncoder writes entries in the layout of the standard library logger:
+// "[LEVEL]2006/01/02 15:04:05 file.go:12: message key=value".
+type textEncoder struct {
+	colored bool
+}
+
+var levelColors = map[Level]func(format string, a ...interface{}) string{
+	TraceLevel: color.BlueString,
+	DebugLevel: color.CyanString,
+	InfoLevel:  color.GreenString,
+	WarnLevel:  color.YellowString,
+	ErrorLevel: color.RedString,
+	FatalLevel: color.RedString,
+}
+
+func (enc *textEncoder) Encode(e *Entry) ([]byte, error) {
+	var buf bytes.Buffer
+	prefix := "[" + e.Level.CapitalString() + "]"
+	if paint, ok := levelColors[e.Level]; ok && enc.colored {
+		prefix = paint(prefix)
+	}
+	buf.WriteString(prefix)
+	buf.WriteString(e.Time.UTC().Format("2006/01/02 15:04:05 "))
+	if caller := textCaller(e); caller != "" {
+		buf.WriteString(caller)
+		buf.WriteString(": ")
+	}
+	buf.WriteString(strings.TrimSuffix(e.Message, "\n"))
+	var causes, stack interface{}
+	for _, f := range e.Fields {
+		switch f.Key {
+		case CausesKey:
+			causes = f.Value
+			continue
+		case StackKey:
+			stack = f.Value
+			continue
+		}
+		buf.WriteByte(' ')
+		buf.WriteString(f.Key)
+		buf.WriteByte('=')
+		fmt.Fprintf(&buf, "%v", f.Value)
+	}
+	buf.WriteByte('\n')
+
+	// The cause chain and the stack are too long for a single line.
+	if chain, ok := causes.([]string); ok {
+		for _, c := range chain {
+			buf.WriteString("\tcaused by: ")
+			buf.WriteString(c)
+			buf.WriteByte('\n')
+		}
+	} else if causes != nil {
+		fmt.Fprintf(&buf, "\tcaused by: %v\n", causes)
+	}
+	if stack != nil {
+		for _, line := range strings.Split(fmt.Sprintf("%v", stack), "\n") {
+			buf.WriteByte('\t')
+			buf.WriteString(line)
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// textCaller keeps the historical behaviour: no caller for info and warn,
+// the short file name for trace, debug and error, and the full path for fatal.
+func textCaller(e *Entry) string {
+	if e.Caller.File == "" {
+		return ""
+	}
+	switch e.Level {
+	case TraceLevel, DebugLevel, ErrorLevel:
+		return filepath.Base(e.Caller.File) + ":" + strconv.Itoa(e.Caller.Line)
+	case FatalLevel:
+		return e.Caller.File + ":" + strconv.Itoa(e.Caller.Line)
+	default:
+		return ""
+	}
+}
+
+// jsonEncoder writes one JSON object per line with ts, level, caller,
+// msg and fields keys.
+type jsonEncoder struct{}
+
+type jsonEntry struct {
+	Time    string                 `json:"ts"`
+	Level   string                 `json:"level"`
+	Caller  string                 `json:"caller,omitempty"`
+	Message string                 `json:"msg"`
+	Fields  map[string]interface{} `json:"fields,omitempty"`
+}
+
+func (enc *jsonEncoder) Encode(e *Entry) ([]byte, error) {
+	je := jsonEntry{
+		Time:    e.Time.UTC().Format(time.RFC3339Nano),
+		Level:   e.Level.String(),
+		Message: strings.TrimSuffix(e.Message, "\n"),
+	}
+	if e.Caller.File != "" {
+		je.Caller = filepath.Base(e.Caller.File) + ":" + strconv.Itoa(e.Caller.Line)
+	}
+	if len(e.Fields) > 0 {
+		je.Fields = make(map[string]interface{}, len(e.Fields))
+		for _, f := range e.Fields {
+			je.Fields[f.Key] = jsonValue(f.Value)
+		}
+	}
+	d, err := json.Marshal(je)
+	if err != nil {
+		// Some field can not be marshalled, fall back to its printed form
+		// rather than losing the whole entry.
+		for k, v := range je.Fields {
+			je.Fields[k] = fmt.Sprintf("%+v", v)
+		}
+		if d, err = json.Marshal(je); err != nil {
+			return nil, errors.Wrap(err, "Encode log entry")
+		}
+	}
+	return append(d, '\n'), nil
+}
+
+// jsonValue makes sure values without a useful JSON form, such as errors
+// and Stringers, still show up readable.
+func jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case error:
+		return val.Error()
+	case json.Marshaler:
+		return val
+	case fmt.Stringer:
+		return val.String()
+	default:
+		return v
+	}
+}
@@ -0,0 +1,88 @@
+package superLog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const defaultExitHookTimeout = 5 * time.Second
+
+var (
+	exitHooksMu     sync.Mutex
+	exitHooks       []func()
+	exitHookTimeout = defaultExitHookTimeout
+	exiting         int32
+)
+
+// RegisterExitHook registers f to run before Fatal exits the process, e.g.
+// to close Kafka writers or Mongo sessions. Hooks run in reverse
+// registration order, each one is given up on after the exit hook timeout.
+func RegisterExitHook(f func()) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHooks = append(exitHooks, f)
+}
+
+// SetExitHookTimeout changes how long each exit hook may run, 5s by default.
+func SetExitHookTimeout(d time.Duration) {
+	exitHooksMu.Lock()
+	defer exitHooksMu.Unlock()
+	exitHookTimeout = d
+}
+
+// Exit runs the exit hooks, flushes pending entries and exits with code.
+func Exit(code int) {
+	runExitHooks()
+	Flush()
+
+	// Record swaps exit under sinksMu.
+	sinksMu.RLock()
+	exitFunc := exit
+	sinksMu.RUnlock()
+	exitFunc(code)
+}
+
+// fatalExit is called after a fatal entry is logged.
+func fatalExit() {
+	Exit(1)
+}
+
+// runExitHooks runs every hook once. A hook calling Fatal exits right away
+// without running the hooks again.
+func runExitHooks() {
+	if !atomic.CompareAndSwapInt32(&exiting, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&exiting, 0)
+
+	exitHooksMu.Lock()
+	hooks := append([]func(){}, exitHooks...)
+	timeout := exitHookTimeout
+	exitHooksMu.Unlock()
+
+	for i := len(hooks) - 1; i >= 0; i-- {
+		runExitHook(hooks[i], timeout)
+	}
+}
+
+func runExitHook(hook func(), timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer func() {
+			if r := recover(); r != nil {
+				Errorf("Exit hook panic: %v", r)
+			}
+		}()
+		hook()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+	case <-timer.C:
+		Warnf("Exit hook does not finish in %v, skip it", timeout)
+	}
+}
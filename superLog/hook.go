@@ -0,0 +1,300 @@
+package superLog
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Hook receives batches of entries at or above the level it was added with,
+// e.g. to post errors to a chat webhook. Fire runs on a dedicated goroutine,
+// a slow hook never blocks the logging call.
+type Hook interface {
+	Fire(entries []Entry) error
+}
+
+// HookFunc adapts a function to a Hook.
+type HookFunc func(entries []Entry) error
+
+func (f HookFunc) Fire(entries []Entry) error {
+	return f(entries)
+}
+
+// SuppressedKey is the field counting identical entries dropped by dedup.
+const SuppressedKey = "suppressed"
+
+const (
+	defaultHookQueueSize    = 1024
+	defaultHookBatchSize    = 20
+	defaultHookBatchWait    = time.Second
+	defaultHookFlushTimeout = 5 * time.Second
+)
+
+type HookOption func(*hookRunner)
+
+// WithHookQueueSize sets how many entries may wait for the hook, entries
+// logged while the queue is full are dropped. 1024 by default.
+func WithHookQueueSize(size int) HookOption {
+	return func(r *hookRunner) {
+		r.queueSize = size
+	}
+}
+
+// WithHookBatch fires the hook once size entries are queued or wait has
+// passed since the first one. 20 entries and 1 second by default.
+func WithHookBatch(size int, wait time.Duration) HookOption {
+	return func(r *hookRunner) {
+		r.batchSize = size
+		r.batchWait = wait
+	}
+}
+
+// WithHookDedup drops entries with the same level and message as one
+// already fired within window. The count of dropped entries is attached
+// to the next identical entry fired, as the "suppressed" field.
+func WithHookDedup(window time.Duration) HookOption {
+	return func(r *hookRunner) {
+		r.dedupWindow = window
+	}
+}
+
+// WithHookFlushTimeout bounds how long Flush waits for the hook. 5s by default.
+func WithHookFlushTimeout(d time.Duration) HookOption {
+	return func(r *hookRunner) {
+		r.flushTimeout = d
+	}
+}
+
+var (
+	hooksMu sync.RWMutex
+	hooks   []*hookRunner
+)
+
+// AddHook fires h with every entry at minLevel or above.
+// Call the returned function to flush and remove the hook.
+func AddHook(minLevel Level, h Hook, opts ...HookOption) (remove func()) {
+	r := &hookRunner{
+		hook:         h,
+		minLevel:     minLevel,
+		queueSize:    defaultHookQueueSize,
+		batchSize:    defaultHookBatchSize,
+		batchWait:    defaultHookBatchWait,
+		flushTimeout: defaultHookFlushTimeout,
+		seen:         make(map[dedupKey]*dedupState),
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	if r.batchSize <= 0 {
+		r.batchSize = 1
+	}
+	r.queue = make(chan *Entry, r.queueSize)
+	r.flushCh = make(chan chan struct{})
+	r.stop = make(chan struct{})
+	r.done = make(chan struct{})
+	go r.run()
+
+	hooksMu.Lock()
+	hooks = append(hooks, r)
+	hooksMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			hooksMu.Lock()
+			for i, hr := range hooks {
+				if hr == r {
+					hooks = append(hooks[:i:i], hooks[i+1:]...)
+					break
+				}
+			}
+			hooksMu.Unlock()
+			close(r.stop)
+			<-r.done
+		})
+	}
+}
+
+// fireHooks queues e for every hook interested in its level. e has been
+// redacted and snapshotted by write, hooks never see the caller's values.
+func fireHooks(e *Entry) {
+	hooksMu.RLock()
+	defer hooksMu.RUnlock()
+	for _, r := range hooks {
+		if e.Level >= r.minLevel {
+			r.enqueue(e)
+		}
+	}
+}
+
+// flushHooks waits until every hook has fired its pending entries.
+func flushHooks() {
+	hooksMu.RLock()
+	runners := append([]*hookRunner(nil), hooks...)
+	hooksMu.RUnlock()
+	for _, r := range runners {
+		r.flush()
+	}
+}
+
+type dedupKey struct {
+	level   Level
+	message string
+}
+
+type dedupState struct {
+	firedAt    time.Time
+	suppressed int
+}
+
+// hookRunner batches and deduplicates entries for a single hook.
+type hookRunner struct {
+	hook         Hook
+	minLevel     Level
+	queueSize    int
+	batchSize    int
+	batchWait    time.Duration
+	dedupWindow  time.Duration
+	flushTimeout time.Duration
+
+	queue   chan *Entry
+	flushCh chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+
+	lock    sync.Mutex
+	dropped int
+	seen    map[dedupKey]*dedupState
+}
+
+func (r *hookRunner) enqueue(e *Entry) {
+	select {
+	case r.queue <- e:
+	default:
+		r.lock.Lock()
+		r.dropped++
+		r.lock.Unlock()
+	}
+}
+
+func (r *hookRunner) flush() {
+	ack := make(chan struct{})
+	timer := time.NewTimer(r.flushTimeout)
+	defer timer.Stop()
+	select {
+	case r.flushCh <- ack:
+	case <-r.done:
+		return
+	case <-timer.C:
+		return
+	}
+	select {
+	case <-ack:
+	case <-timer.C:
+	}
+}
+
+func (r *hookRunner) run() {
+	defer close(r.done)
+	var batch []Entry
+	timer := time.NewTimer(r.batchWait)
+	timer.Stop()
+
+	fire := func() {
+		if len(batch) > 0 {
+			r.fire(batch)
+			batch = nil
+		}
+	}
+	add := func(e *Entry) {
+		if entry, ok := r.dedup(e); ok {
+			if len(batch) == 0 {
+				timer.Reset(r.batchWait)
+			}
+			batch = append(batch, entry)
+		}
+		if len(batch) >= r.batchSize {
+			timer.Stop()
+			fire()
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case e := <-r.queue:
+				add(e)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case e := <-r.queue:
+			add(e)
+		case <-timer.C:
+			fire()
+		case ack := <-r.flushCh:
+			drain()
+			timer.Stop()
+			fire()
+			close(ack)
+		case <-r.stop:
+			drain()
+			timer.Stop()
+			fire()
+			return
+		}
+	}
+}
+
+// dedup returns the entry to fire, or false if an identical one was fired
+// within the dedup window.
+func (r *hookRunner) dedup(e *Entry) (Entry, bool) {
+	entry := *e
+	if r.dedupWindow <= 0 {
+		return entry, true
+	}
+	key := dedupKey{level: e.Level, message: e.Message}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	state, ok := r.seen[key]
+	if ok && e.Time.Sub(state.firedAt) < r.dedupWindow {
+		state.suppressed++
+		return entry, false
+	}
+	if ok && state.suppressed > 0 {
+		entry.Fields = append(append([]Field(nil), e.Fields...), Field{Key: SuppressedKey, Value: state.suppressed})
+	}
+	r.seen[key] = &dedupState{firedAt: e.Time}
+	// Forget old entries so the map does not grow forever.
+	for k, s := range r.seen {
+		if e.Time.Sub(s.firedAt) >= 2*r.dedupWindow {
+			delete(r.seen, k)
+		}
+	}
+	return entry, true
+}
+
+// fire calls the hook. Failures are reported straight to stderr, logging
+// them would fire the hook again.
+func (r *hookRunner) fire(batch []Entry) {
+	r.lock.Lock()
+	dropped := r.dropped
+	r.dropped = 0
+	r.lock.Unlock()
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "superLog: hook queue is full, dropped %d entries\n", dropped)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			fmt.Fprintln(os.Stderr, "superLog: hook panic:", p)
+		}
+	}()
+	if err := r.hook.Fire(batch); err != nil {
+		fmt.Fprintln(os.Stderr, "superLog: fire hook:", err)
+	}
+}
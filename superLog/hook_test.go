@@ -0,0 +1,43 @@
+package superLog
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestHookSnapshotsFields(t *testing.T) {
+	Record(t)
+	var (
+		lock  sync.Mutex
+		fired []Entry
+	)
+	remove := AddHook(ErrorLevel, HookFunc(func(entries []Entry) error {
+		lock.Lock()
+		defer lock.Unlock()
+		fired = append(fired, entries...)
+		return nil
+	}))
+	defer remove()
+
+	stats := map[string]int{"failed": 0}
+	for i := 0; i < 50; i++ {
+		stats["failed"] = i
+		Errorw("batch", "stats", stats, "password", "hunter2")
+	}
+	Flush()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(fired) != 50 {
+		t.Fatalf("hook fired %d entries, want 50", len(fired))
+	}
+	for i, e := range fired {
+		if v, _ := e.Field("stats"); fmt.Sprint(v) != fmt.Sprintf("map[failed:%d]", i) {
+			t.Fatalf("entry %d: stats = %v", i, v)
+		}
+		if v, _ := e.Field("password"); v != RedactedValue {
+			t.Fatalf("entry %d: password = %v, want it redacted", i, v)
+		}
+	}
+}
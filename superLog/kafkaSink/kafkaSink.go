@@ -0,0 +1,448 @@
+package kafkaSink
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/superwhys/superGo/superKafka/producer"
+	"github.com/superwhys/superGo/superLog"
+)
+
+// ServiceKey is the field holding the service name in every published entry.
+const ServiceKey = "service"
+
+const (
+	defaultQueueSize     = 4096
+	defaultBatchSize     = 100
+	defaultBatchWait     = time.Second
+	defaultWriteTimeout  = 10 * time.Second
+	defaultRetryInterval = 10 * time.Second
+	defaultMaxSpoolSize  = 100 << 20
+	defaultFlushTimeout  = 5 * time.Second
+)
+
+// ignoredPackages are never published: the sink writes through them, their
+// own errors would be logged again through the sink while it is failing to
+// write. The consumer logs are published like any other.
+var ignoredPackages = []string{
+	"github.com/superwhys/superGo/superKafka/producer",
+	"github.com/segmentio/kafka-go",
+}
+
+// writer is the part of producer.SuperKafkaWriter used by the sink.
+type writer interface {
+	WriteRawMessages(ctx context.Context, key string, values ...[]byte) error
+	Close() error
+}
+
+type OptionKafkaSinkFunc func(*KafkaSink)
+
+// KafkaSink is a superLog.Sink publishing entries as JSON to a kafka topic,
+// one message per entry keyed by the service name.
+//
+// Entries are queued and published in batches by a background goroutine,
+// a slow or unavailable broker never blocks the logging call. Batches which
+// fail to be published are appended to a spool file, see WithSpoolDir, and
+// published again once the broker is back.
+//
+// Entries logged from superKafka, kafka-go or this package are never
+// published, so that a failing producer does not log into itself.
+type KafkaSink struct {
+	writer        writer
+	service       string
+	topic         string
+	minLevel      superLog.Level
+	encoder       superLog.Encoder
+	queueSize     int
+	batchSize     int
+	batchWait     time.Duration
+	writeTimeout  time.Duration
+	spoolDir      string
+	maxSpoolSize  int64
+	retryInterval time.Duration
+	flushTimeout  time.Duration
+
+	queue   chan []byte
+	flushCh chan chan struct{}
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+
+	lock    sync.Mutex
+	dropped int
+	retryAt time.Time
+}
+
+// New returns a sink publishing to the topic of w. Add it with
+//
+//	w := producer.InitWriter(kafkaIps, "logs")
+//	superLog.AddSink(kafkaSink.New(w, "myservice", kafkaSink.WithSpoolDir("/var/spool/myservice")))
+func New(w *producer.SuperKafkaWriter, service string, opts ...OptionKafkaSinkFunc) *KafkaSink {
+	return newSink(w, w.SuperWriterConfig.Topic, service, opts...)
+}
+
+// Dial creates the writer for topic on the brokers at kafkaIps and returns
+// a sink publishing to it. The writer is closed with the sink.
+func Dial(kafkaIps, topic, service string, opts ...OptionKafkaSinkFunc) *KafkaSink {
+	return New(producer.InitWriter(kafkaIps, topic), service, opts...)
+}
+
+func newSink(w writer, topic, service string, opts ...OptionKafkaSinkFunc) *KafkaSink {
+	enc, _ := superLog.NewEncoder(superLog.FormatJSON)
+	ks := &KafkaSink{
+		writer:        w,
+		service:       service,
+		topic:         topic,
+		minLevel:      superLog.TraceLevel,
+		encoder:       enc,
+		queueSize:     defaultQueueSize,
+		batchSize:     defaultBatchSize,
+		batchWait:     defaultBatchWait,
+		writeTimeout:  defaultWriteTimeout,
+		maxSpoolSize:  defaultMaxSpoolSize,
+		retryInterval: defaultRetryInterval,
+		flushTimeout:  defaultFlushTimeout,
+	}
+	for _, opt := range opts {
+		opt(ks)
+	}
+	if ks.batchSize <= 0 {
+		ks.batchSize = 1
+	}
+	ks.queue = make(chan []byte, ks.queueSize)
+	ks.flushCh = make(chan chan struct{})
+	ks.stop = make(chan struct{})
+	ks.done = make(chan struct{})
+	go ks.run()
+	return ks
+}
+
+// WithMinLevel drops entries below lvl for this sink only.
+func WithMinLevel(lvl superLog.Level) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		ks.minLevel = lvl
+	}
+}
+
+// WithEncoder replaces the JSON encoder used for the message values.
+func WithEncoder(enc superLog.Encoder) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		ks.encoder = enc
+	}
+}
+
+// WithQueueSize sets how many entries may wait to be published, entries
+// logged while the queue is full are dropped. 4096 by default or when
+// size <= 0.
+func WithQueueSize(size int) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		if size > 0 {
+			ks.queueSize = size
+		}
+	}
+}
+
+// WithBatch publishes once size entries are queued or wait has passed since
+// the first one. 100 entries and 1 second by default, a wait <= 0 keeps
+// the default.
+func WithBatch(size int, wait time.Duration) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		ks.batchSize = size
+		if wait > 0 {
+			ks.batchWait = wait
+		}
+	}
+}
+
+// WithWriteTimeout bounds every publish to the broker. 10s by default or
+// when timeout <= 0.
+func WithWriteTimeout(timeout time.Duration) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		if timeout > 0 {
+			ks.writeTimeout = timeout
+		}
+	}
+}
+
+// WithSpoolDir keeps the batches which could not be published in a file of
+// dir, at most maxSize bytes, 100MB when maxSize <= 0. Without a spool dir
+// these batches are dropped.
+func WithSpoolDir(dir string, maxSize int64) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		ks.spoolDir = dir
+		if maxSize > 0 {
+			ks.maxSpoolSize = maxSize
+		}
+	}
+}
+
+// WithRetryInterval sets how long to wait after a failed publish before
+// trying the broker again. 10s by default or when d <= 0.
+func WithRetryInterval(d time.Duration) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		if d > 0 {
+			ks.retryInterval = d
+		}
+	}
+}
+
+// WithFlushTimeout bounds how long superLog.Flush waits for the sink. 5s by
+// default or when d <= 0.
+func WithFlushTimeout(d time.Duration) OptionKafkaSinkFunc {
+	return func(ks *KafkaSink) {
+		if d > 0 {
+			ks.flushTimeout = d
+		}
+	}
+}
+
+// Write encodes e and queues it, it never blocks on the broker.
+func (ks *KafkaSink) Write(e *superLog.Entry) error {
+	if e.Level < ks.minLevel || ignored(e.Caller.Function) {
+		return nil
+	}
+	entry := *e
+	entry.Fields = append(append([]superLog.Field(nil), e.Fields...), superLog.Field{Key: ServiceKey, Value: ks.service})
+	data, err := ks.encoder.Encode(&entry)
+	if err != nil {
+		return errors.Wrap(err, "Encode kafka log entry")
+	}
+	data = bytes.TrimSuffix(data, []byte("\n"))
+
+	select {
+	case <-ks.stop:
+		return nil
+	default:
+	}
+	select {
+	case ks.queue <- data:
+	default:
+		ks.lock.Lock()
+		ks.dropped++
+		ks.lock.Unlock()
+	}
+	return nil
+}
+
+// Sync waits until the queued entries are published or spooled.
+func (ks *KafkaSink) Sync() error {
+	ack := make(chan struct{})
+	timer := time.NewTimer(ks.flushTimeout)
+	defer timer.Stop()
+	select {
+	case ks.flushCh <- ack:
+	case <-ks.done:
+		return nil
+	case <-timer.C:
+		return errors.New("Flush kafka sink timeout")
+	}
+	select {
+	case <-ack:
+		return nil
+	case <-timer.C:
+		return errors.New("Flush kafka sink timeout")
+	}
+}
+
+// Close publishes the queued entries and closes the writer.
+func (ks *KafkaSink) Close() error {
+	ks.once.Do(func() {
+		close(ks.stop)
+	})
+	<-ks.done
+	return ks.writer.Close()
+}
+
+func ignored(function string) bool {
+	for _, pkg := range ignoredPackages {
+		if strings.HasPrefix(function, pkg+"/") || strings.HasPrefix(function, pkg+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func (ks *KafkaSink) run() {
+	defer close(ks.done)
+	var batch [][]byte
+	timer := time.NewTimer(ks.batchWait)
+	timer.Stop()
+	retry := time.NewTicker(ks.retryInterval)
+	defer retry.Stop()
+
+	publish := func() {
+		if len(batch) > 0 {
+			ks.publish(batch)
+			batch = nil
+		}
+	}
+	add := func(data []byte) {
+		if len(batch) == 0 {
+			timer.Reset(ks.batchWait)
+		}
+		batch = append(batch, data)
+		if len(batch) >= ks.batchSize {
+			timer.Stop()
+			publish()
+		}
+	}
+	drain := func() {
+		for {
+			select {
+			case data := <-ks.queue:
+				add(data)
+			default:
+				return
+			}
+		}
+	}
+
+	for {
+		select {
+		case data := <-ks.queue:
+			add(data)
+		case <-timer.C:
+			publish()
+		case <-retry.C:
+			ks.replay()
+		case ack := <-ks.flushCh:
+			drain()
+			timer.Stop()
+			publish()
+			close(ack)
+		case <-ks.stop:
+			drain()
+			timer.Stop()
+			publish()
+			return
+		}
+	}
+}
+
+// publish writes batch to the broker, or to the spool while the broker is
+// unavailable or older batches are still spooled, to keep them in order.
+// Failures are reported straight to stderr, logging them would come back
+// to this sink.
+func (ks *KafkaSink) publish(batch [][]byte) {
+	ks.lock.Lock()
+	dropped := ks.dropped
+	ks.dropped = 0
+	ks.lock.Unlock()
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "superLog: kafka sink queue is full, dropped %d entries\n", dropped)
+	}
+
+	if time.Now().Before(ks.retryAt) || ks.spooled() {
+		ks.spool(batch)
+		ks.replay()
+		return
+	}
+	if err := ks.write(batch); err != nil {
+		fmt.Fprintln(os.Stderr, "superLog: kafka sink:", err)
+		ks.retryAt = time.Now().Add(ks.retryInterval)
+		ks.spool(batch)
+	}
+}
+
+func (ks *KafkaSink) write(batch [][]byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ks.writeTimeout)
+	defer cancel()
+	return ks.writer.WriteRawMessages(ctx, ks.service, batch...)
+}
+
+func (ks *KafkaSink) spoolFile() string {
+	name := strings.NewReplacer("/", "_", string(filepath.Separator), "_").Replace(ks.topic)
+	return filepath.Join(ks.spoolDir, name+".spool")
+}
+
+// spooled reports whether batches are waiting in the spool file.
+func (ks *KafkaSink) spooled() bool {
+	if ks.spoolDir == "" {
+		return false
+	}
+	info, err := os.Stat(ks.spoolFile())
+	return err == nil && info.Size() > 0
+}
+
+// spool appends batch to the spool file, one entry per line.
+func (ks *KafkaSink) spool(batch [][]byte) {
+	if ks.spoolDir == "" {
+		fmt.Fprintf(os.Stderr, "superLog: kafka sink has no spool dir, dropped %d entries\n", len(batch))
+		return
+	}
+	if err := os.MkdirAll(ks.spoolDir, 0755); err != nil {
+		fmt.Fprintln(os.Stderr, "superLog: kafka sink spool:", err)
+		return
+	}
+	f, err := os.OpenFile(ks.spoolFile(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "superLog: kafka sink spool:", err)
+		return
+	}
+	defer f.Close()
+
+	var size int64
+	if info, err := f.Stat(); err == nil {
+		size = info.Size()
+	}
+	w := bufio.NewWriter(f)
+	for i, data := range batch {
+		if size+int64(len(data))+1 > ks.maxSpoolSize {
+			fmt.Fprintf(os.Stderr, "superLog: kafka sink spool is full, dropped %d entries\n", len(batch)-i)
+			break
+		}
+		w.Write(data)
+		w.WriteByte('\n')
+		size += int64(len(data)) + 1
+	}
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, "superLog: kafka sink spool:", err)
+	}
+}
+
+// replay publishes the spooled entries once the retry interval has passed.
+// The entries which could not be published stay in the spool file.
+func (ks *KafkaSink) replay() {
+	if time.Now().Before(ks.retryAt) || !ks.spooled() {
+		return
+	}
+	content, err := os.ReadFile(ks.spoolFile())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "superLog: kafka sink spool:", err)
+		return
+	}
+	lines := bytes.Split(bytes.TrimSuffix(content, []byte("\n")), []byte("\n"))
+	for len(lines) > 0 {
+		n := ks.batchSize
+		if n > len(lines) {
+			n = len(lines)
+		}
+		if err := ks.write(lines[:n]); err != nil {
+			fmt.Fprintln(os.Stderr, "superLog: kafka sink replay:", err)
+			ks.retryAt = time.Now().Add(ks.retryInterval)
+			break
+		}
+		lines = lines[n:]
+	}
+
+	if len(lines) == 0 {
+		err = os.Remove(ks.spoolFile())
+	} else {
+		rest := append(bytes.Join(lines, []byte("\n")), '\n')
+		tmp := ks.spoolFile() + ".tmp"
+		if err = os.WriteFile(tmp, rest, 0644); err == nil {
+			err = os.Rename(tmp, ks.spoolFile())
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "superLog: kafka sink spool:", err)
+	}
+}
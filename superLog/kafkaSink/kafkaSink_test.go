@@ -0,0 +1,133 @@
+package kafkaSink
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/superwhys/superGo/superLog"
+)
+
+type fakeWriter struct {
+	lock   sync.Mutex
+	down   bool
+	keys   []string
+	values [][]byte
+}
+
+func (w *fakeWriter) WriteRawMessages(ctx context.Context, key string, values ...[]byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.down {
+		return errors.New("broker unavailable")
+	}
+	for _, v := range values {
+		w.keys = append(w.keys, key)
+		w.values = append(w.values, v)
+	}
+	return nil
+}
+
+func (w *fakeWriter) Close() error {
+	return nil
+}
+
+func (w *fakeWriter) setDown(down bool) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.down = down
+}
+
+func (w *fakeWriter) messages() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var msgs []string
+	for _, v := range w.values {
+		var payload struct {
+			Msg    string                 `json:"msg"`
+			Fields map[string]interface{} `json:"fields"`
+		}
+		if err := json.Unmarshal(v, &payload); err == nil {
+			msgs = append(msgs, payload.Msg+"@"+payload.Fields[ServiceKey].(string))
+		}
+	}
+	return msgs
+}
+
+func entry(msg, function string) *superLog.Entry {
+	return &superLog.Entry{
+		Time:    time.Now(),
+		Level:   superLog.ErrorLevel,
+		Caller:  runtime.Frame{Function: function},
+		Message: msg,
+	}
+}
+
+func TestKafkaSink(t *testing.T) {
+	dir := t.TempDir()
+	w := &fakeWriter{}
+	ks := newSink(w, "logs", "svc", WithBatch(10, time.Hour), WithSpoolDir(dir, 0), WithRetryInterval(time.Hour))
+	defer ks.Close()
+
+	ks.Write(entry("first", "main.main"))
+	ks.Write(entry("Write kafka message", "github.com/superwhys/superGo/superKafka/producer.writeMessage"))
+	ks.Write(entry("Decode kafka message", "github.com/superwhys/superGo/superKafka/consumer.readMessage"))
+	if err := ks.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.messages(); len(got) != 2 || got[0] != "first@svc" || got[1] != "Decode kafka message@svc" || w.keys[0] != "svc" {
+		t.Fatalf("published %v, want first and the consumer error keyed by svc", got)
+	}
+
+	// The broker goes down: the batch is spooled, and later batches too to
+	// keep the order, until the retry interval has passed.
+	w.setDown(true)
+	ks.Write(entry("second", "main.main"))
+	ks.Sync()
+	w.setDown(false)
+	ks.Write(entry("third", "main.main"))
+	ks.Sync()
+	if got := w.messages(); len(got) != 2 {
+		t.Fatalf("published %v while waiting to retry", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logs.spool")); err != nil {
+		t.Fatalf("spool file: %v", err)
+	}
+
+	ks.retryAt = time.Time{}
+	ks.Write(entry("fourth", "main.main"))
+	ks.Sync()
+	want := []string{"first@svc", "Decode kafka message@svc", "second@svc", "third@svc", "fourth@svc"}
+	got := w.messages()
+	if len(got) != len(want) {
+		t.Fatalf("published %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("published %v, want %v", got, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "logs.spool")); !os.IsNotExist(err) {
+		t.Fatalf("spool file not removed after replay: %v", err)
+	}
+}
+
+func TestKafkaSinkInvalidOptions(t *testing.T) {
+	w := &fakeWriter{}
+	ks := newSink(w, "logs", "svc", WithQueueSize(0), WithRetryInterval(0), WithBatch(0, 0), WithWriteTimeout(-1), WithFlushTimeout(0))
+	defer ks.Close()
+
+	ks.Write(entry("first", "main.main"))
+	if err := ks.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := w.messages(); len(got) != 1 {
+		t.Fatalf("published %v, want the entry", got)
+	}
+}
@@ -0,0 +1,212 @@
+package superLog
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Level is the severity of a log entry.
+type Level int8
+
+const (
+	TraceLevel Level = iota
+	DebugLevel
+	InfoLevel
+	WarnLevel
+	ErrorLevel
+	FatalLevel
+)
+
+var levelNames = map[Level]string{
+	TraceLevel: "trace",
+	DebugLevel: "debug",
+	InfoLevel:  "info",
+	WarnLevel:  "warn",
+	ErrorLevel: "error",
+	FatalLevel: "fatal",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// CapitalString returns the upper case name, as used in the text prefix.
+func (l Level) CapitalString() string {
+	return strings.ToUpper(l.String())
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN".
+func ParseLevel(name string) (Level, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "warning" {
+		return WarnLevel, nil
+	}
+	for lvl, n := range levelNames {
+		if n == name {
+			return lvl, nil
+		}
+	}
+	return InfoLevel, errors.Errorf("unknown log level: %q", name)
+}
+
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(l.String()), nil
+}
+
+func (l *Level) UnmarshalText(text []byte) error {
+	lvl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = lvl
+	return nil
+}
+
+// currentLevel is read on every log call, so it is accessed atomically.
+var currentLevel = int32(InfoLevel)
+
+// SetLevel changes the minimum level written. It is safe to call at any time.
+func SetLevel(lvl Level) {
+	atomic.StoreInt32(&currentLevel, int32(lvl))
+}
+
+// GetLevel returns the minimum level written.
+func GetLevel() Level {
+	return Level(atomic.LoadInt32(&currentLevel))
+}
+
+// Enabled reports whether entries at lvl are written.
+func Enabled(lvl Level) bool {
+	return lvl >= GetLevel()
+}
+
+// EnableDebug is a shortcut for SetLevel(DebugLevel).
+func EnableDebug() {
+	SetLevel(DebugLevel)
+}
+
+// ToggleLevelOnSignal switches between the current level and lvl every time
+// sig is received, e.g. ToggleLevelOnSignal(syscall.SIGUSR1, DebugLevel).
+// Call the returned function to stop listening.
+func ToggleLevelOnSignal(sig os.Signal, lvl Level) (stop func()) {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, sig)
+	go func() {
+		previous := GetLevel()
+		for {
+			select {
+			case <-ch:
+				current := GetLevel()
+				if current == lvl {
+					SetLevel(previous)
+				} else {
+					previous = current
+					SetLevel(lvl)
+				}
+				Warnf("Log level switched to %s by signal %s", GetLevel(), sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(ch)
+		close(done)
+	}
+}
+
+type levelPayload struct {
+	Level   *Level  `json:"level,omitempty"`
+	VModule *string `json:"vmodule,omitempty"`
+}
+
+// LevelHandler returns an http.Handler to inspect and change the level and
+// the vmodule overrides of a running process.
+//
+// GET returns {"level":"info","vmodule":"superMongo=debug"}. PUT or POST
+// changes either of them, from a JSON body {"level":"debug"} or from the
+// `level` and `vmodule` query/form values.
+//
+// The handler has no authentication. Mount it on an internal listener or
+// behind an auth middleware, never on a public router, e.g.
+//
+//	admin := http.NewServeMux()
+//	admin.Handle("/log/level", superLog.LevelHandler())
+//	go http.ListenAndServe("127.0.0.1:6060", admin)
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			payload, err := readLevelPayload(r)
+			if err != nil {
+				writeLevelError(w, err)
+				return
+			}
+			if payload.VModule != nil {
+				if err := SetVModule(*payload.VModule); err != nil {
+					writeLevelError(w, err)
+					return
+				}
+				Warnf("Log vmodule switched to %q by %s", *payload.VModule, r.RemoteAddr)
+			}
+			if payload.Level != nil {
+				SetLevel(*payload.Level)
+				Warnf("Log level switched to %s by %s", *payload.Level, r.RemoteAddr)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			writeJSON(w, map[string]string{"error": "only GET, PUT and POST are supported"})
+			return
+		}
+		lvl, vmodule := GetLevel(), GetVModule()
+		writeJSON(w, levelPayload{Level: &lvl, VModule: &vmodule})
+	})
+}
+
+func readLevelPayload(r *http.Request) (*levelPayload, error) {
+	payload := &levelPayload{}
+	if err := r.ParseForm(); err != nil {
+		return nil, errors.Wrap(err, "Parse form")
+	}
+	if _, ok := r.Form["level"]; ok {
+		lvl, err := ParseLevel(r.Form.Get("level"))
+		if err != nil {
+			return nil, err
+		}
+		payload.Level = &lvl
+	}
+	if _, ok := r.Form["vmodule"]; ok {
+		vmodule := r.Form.Get("vmodule")
+		payload.VModule = &vmodule
+	}
+	if payload.Level != nil || payload.VModule != nil {
+		return payload, nil
+	}
+	if err := json.NewDecoder(r.Body).Decode(payload); err != nil {
+		return nil, errors.Wrap(err, "Decode level")
+	}
+	return payload, nil
+}
+
+func writeLevelError(w http.ResponseWriter, err error) {
+	w.WriteHeader(http.StatusBadRequest)
+	writeJSON(w, map[string]string{"error": err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		Error("Write response", err)
+	}
+}
@@ -3,83 +3,128 @@ package superLog
 import (
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"strings"
-
-	"github.com/fatih/color"
+	"sync"
 )
 
 var (
-	debug                                        = false
-	infoLog, debugLog, warnLog, errLog, fatalLog *log.Logger
+	encMu   sync.RWMutex
+	encoder Encoder = &textEncoder{colored: true}
+
+	// exit is called by Fatal, replaced under sinksMu while a Recorder is
+	// installed.
+	exit = os.Exit
 )
 
-func init() {
-	infoLog = log.New(os.Stdout, color.GreenString("[INFO]"), log.LstdFlags|log.LUTC)
-	debugLog = log.New(os.Stdout, color.CyanString("[DEBUG]"), log.LstdFlags|log.Lshortfile|log.LUTC)
-	errLog = log.New(os.Stderr, color.RedString("[ERROR]"), log.LstdFlags|log.Lshortfile|log.LUTC)
-	warnLog = log.New(os.Stdout, color.YellowString("[WARN]"), log.LstdFlags|log.LUTC)
-	fatalLog = log.New(os.Stderr, color.RedString("[FATAL]"), log.LstdFlags|log.Llongfile|log.LUTC)
+// SetFormat selects one of the builtin output formats:
+// "console" (colored, the default), "text" or "json".
+func SetFormat(format string) error {
+	enc, err := NewEncoder(format)
+	if err != nil {
+		return err
+	}
+	SetEncoder(enc)
+	return nil
+}
+
+// SetEncoder replaces the encoder used by sinks without their own encoder.
+func SetEncoder(enc Encoder) {
+	encMu.Lock()
+	defer encMu.Unlock()
+	encoder = enc
 }
 
-func EnableDebug() {
-	debug = true
+func currentEncoder() Encoder {
+	encMu.RLock()
+	defer encMu.RUnlock()
+	return encoder
 }
 
 func Error(v ...interface{}) {
-	if v[0] != nil {
-		errLog.Output(2, fmt.Sprintln(v...))
+	if len(v) > 0 && v[0] != nil {
+		std.output(ErrorLevel, fmt.Sprintln(v...), errorFields(v))
 	}
 }
 
 func PanicError(err error, msg ...interface{}) {
 	if err != nil {
 		if len(msg) > 0 {
-			errLog.Output(2, err.Error()+":"+fmt.Sprint(msg...))
+			std.output(ErrorLevel, err.Error()+":"+fmt.Sprint(msg...), errorFields([]interface{}{err}))
 		} else {
-			errLog.Output(2, err.Error())
+			std.output(ErrorLevel, err.Error(), errorFields([]interface{}{err}))
 		}
 		panic(err)
 	}
 }
 
 func Warn(v ...interface{}) {
-	if v[0] != nil {
-		warnLog.Output(2, fmt.Sprintln(v...))
+	if len(v) > 0 && v[0] != nil {
+		std.output(WarnLevel, fmt.Sprintln(v...), nil)
 	}
 }
 
 func Info(v ...interface{}) {
-	if v[0] != nil {
-		infoLog.Output(2, fmt.Sprintln(v...))
+	if len(v) > 0 && v[0] != nil {
+		std.output(InfoLevel, fmt.Sprintln(v...), nil)
 	}
 }
 
 func Debug(v ...interface{}) {
-	if debug && v[0] != nil {
-		debugLog.Output(2, fmt.Sprintln(v...))
+	if len(v) > 0 && v[0] != nil {
+		std.output(DebugLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func Trace(v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		std.output(TraceLevel, fmt.Sprintln(v...), nil)
 	}
 }
 
 func Infof(msg string, v ...interface{}) {
-	strMsg := fmt.Sprintf(msg, v...)
-	Info(strMsg)
+	std.output(InfoLevel, fmt.Sprintf(msg, v...), nil)
 }
 
 func Debugf(msg string, v ...interface{}) {
-	strMsg := fmt.Sprintf(msg, v...)
-	Debug(strMsg)
+	std.output(DebugLevel, fmt.Sprintf(msg, v...), nil)
+}
+
+func Tracef(msg string, v ...interface{}) {
+	std.output(TraceLevel, fmt.Sprintf(msg, v...), nil)
 }
 
 func Warnf(msg string, v ...interface{}) {
-	strMsg := fmt.Sprintf(msg, v...)
-	Warn(strMsg)
+	std.output(WarnLevel, fmt.Sprintf(msg, v...), nil)
 }
 
 func Errorf(msg string, v ...interface{}) {
-	strMsg := fmt.Sprintf(msg, v...)
-	Error(strMsg)
+	std.output(ErrorLevel, fmt.Sprintf(msg, v...), errorFields(v))
+}
+
+// Infow logs msg with the given key-value pairs as fields.
+func Infow(msg string, keysAndValues ...interface{}) {
+	std.output(InfoLevel, msg, toFields(keysAndValues))
+}
+
+// Debugw logs msg with the given key-value pairs as fields.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	std.output(DebugLevel, msg, toFields(keysAndValues))
+}
+
+// Tracew logs msg with the given key-value pairs as fields.
+func Tracew(msg string, keysAndValues ...interface{}) {
+	std.output(TraceLevel, msg, toFields(keysAndValues))
+}
+
+// Warnw logs msg with the given key-value pairs as fields.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	std.output(WarnLevel, msg, toFields(keysAndValues))
+}
+
+// Errorw logs msg with the given key-value pairs as fields.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	std.output(ErrorLevel, msg, append(toFields(keysAndValues), errorFields(keysAndValues)...))
 }
 
 func Fatal(v ...interface{}) {
@@ -87,19 +132,30 @@ func Fatal(v ...interface{}) {
 	for _, i := range v {
 		msg = append(msg, fmt.Sprintf("%v", i))
 	}
-	fatalLog.Output(2, strings.Join(msg, " "))
-	os.Exit(1)
+	std.output(FatalLevel, strings.Join(msg, " "), errorFields(v))
+	fatalExit()
+}
+
+// Fatalw logs msg with the given key-value pairs as fields and exits.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	std.output(FatalLevel, msg, append(toFields(keysAndValues), errorFields(keysAndValues)...))
+	fatalExit()
 }
 
 func Fatalf(msg string, v ...interface{}) {
-	Fatal(msg, v)
+	std.output(FatalLevel, fmt.Sprintf(msg, v...), errorFields(v))
+	fatalExit()
 }
 
+// Jsonify returns v as indented JSON, secrets masked as in log output.
 func Jsonify(v interface{}) string {
 	d, err := json.MarshalIndent(v, "", "  ")
 	if err != nil {
 		Error(err)
 		panic(err)
 	}
+	if r := getRedactor(); r != nil {
+		return r.json(string(d))
+	}
 	return string(d)
 }
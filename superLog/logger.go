@@ -0,0 +1,211 @@
+package superLog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Field is a single key-value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Logger writes entries carrying a fixed set of fields.
+// The zero value is not usable, use New() or With() to get one.
+type Logger struct {
+	fields []Field
+	every  time.Duration
+}
+
+var std = New()
+
+// New returns a Logger without any fields.
+func New() *Logger {
+	return &Logger{}
+}
+
+// With returns a child logger carrying the given key-value pairs
+// in addition to the fields of the parent.
+func With(keysAndValues ...interface{}) *Logger {
+	return std.With(keysAndValues...)
+}
+
+// With returns a child logger carrying the given key-value pairs
+// in addition to the fields of l.
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	child := l.clone()
+	child.fields = append(child.fields, toFields(keysAndValues)...)
+	return child
+}
+
+func (l *Logger) clone() *Logger {
+	child := *l
+	child.fields = append(make([]Field, 0, len(l.fields)), l.fields...)
+	return &child
+}
+
+// Fields returns a copy of the fields carried by l.
+func (l *Logger) Fields() []Field {
+	return append([]Field(nil), l.fields...)
+}
+
+// toFields pairs up keysAndValues. A key without a value, or a key which
+// is not a string, is still kept so that nothing given by the caller is lost.
+func toFields(keysAndValues []interface{}) []Field {
+	var fields []Field
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", keysAndValues[i])
+		}
+		if i+1 >= len(keysAndValues) {
+			fields = append(fields, Field{Key: "!BADKEY", Value: key})
+			break
+		}
+		fields = append(fields, Field{Key: key, Value: keysAndValues[i+1]})
+	}
+	return fields
+}
+
+// output builds an entry from msg, the fields of l and extra fields.
+// It must be called directly from the exported logging method so the
+// reported caller is the user's code.
+func (l *Logger) output(lvl Level, msg string, extra []Field) {
+	if !anyEnabled(lvl) {
+		return
+	}
+	pc := callerPC(3)
+	if !enabledAt(lvl, pc) || !sample(pc, lvl, l.every) {
+		return
+	}
+	fields := l.fields
+	if len(extra) > 0 {
+		fields = append(append([]Field(nil), l.fields...), extra...)
+	}
+	if lvl >= ErrorLevel && errorStackEnabled() && !hasField(fields, StackKey) {
+		fields = append(append([]Field(nil), fields...), Field{Key: StackKey, Value: callerStack(3)})
+	}
+	write(&Entry{
+		Time:    time.Now(),
+		Level:   lvl,
+		Caller:  frameOf(pc),
+		Message: strings.TrimSuffix(msg, "\n"),
+		Fields:  fields,
+	})
+}
+
+// callerPC returns the program counter skip levels above the function
+// calling callerPC.
+func callerPC(skip int) uintptr {
+	pc := make([]uintptr, 1)
+	if runtime.Callers(skip+1, pc) == 0 {
+		return 0
+	}
+	return pc[0]
+}
+
+func frameOf(pc uintptr) runtime.Frame {
+	if pc == 0 {
+		return runtime.Frame{}
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return frame
+}
+
+func (l *Logger) Error(v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		l.output(ErrorLevel, fmt.Sprintln(v...), errorFields(v))
+	}
+}
+
+func (l *Logger) Warn(v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		l.output(WarnLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func (l *Logger) Info(v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		l.output(InfoLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func (l *Logger) Debug(v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		l.output(DebugLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func (l *Logger) Trace(v ...interface{}) {
+	if len(v) > 0 && v[0] != nil {
+		l.output(TraceLevel, fmt.Sprintln(v...), nil)
+	}
+}
+
+func (l *Logger) Errorf(msg string, v ...interface{}) {
+	l.output(ErrorLevel, fmt.Sprintf(msg, v...), errorFields(v))
+}
+
+func (l *Logger) Warnf(msg string, v ...interface{}) {
+	l.output(WarnLevel, fmt.Sprintf(msg, v...), nil)
+}
+
+func (l *Logger) Infof(msg string, v ...interface{}) {
+	l.output(InfoLevel, fmt.Sprintf(msg, v...), nil)
+}
+
+func (l *Logger) Debugf(msg string, v ...interface{}) {
+	l.output(DebugLevel, fmt.Sprintf(msg, v...), nil)
+}
+
+// Errorw logs msg with the given key-value pairs as fields.
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.output(ErrorLevel, msg, append(toFields(keysAndValues), errorFields(keysAndValues)...))
+}
+
+// Warnw logs msg with the given key-value pairs as fields.
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.output(WarnLevel, msg, toFields(keysAndValues))
+}
+
+// Infow logs msg with the given key-value pairs as fields.
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.output(InfoLevel, msg, toFields(keysAndValues))
+}
+
+// Debugw logs msg with the given key-value pairs as fields.
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.output(DebugLevel, msg, toFields(keysAndValues))
+}
+
+func (l *Logger) Tracef(msg string, v ...interface{}) {
+	l.output(TraceLevel, fmt.Sprintf(msg, v...), nil)
+}
+
+// Tracew logs msg with the given key-value pairs as fields.
+func (l *Logger) Tracew(msg string, keysAndValues ...interface{}) {
+	l.output(TraceLevel, msg, toFields(keysAndValues))
+}
+
+func (l *Logger) Fatal(v ...interface{}) {
+	var msg []string
+	for _, i := range v {
+		msg = append(msg, fmt.Sprintf("%v", i))
+	}
+	l.output(FatalLevel, strings.Join(msg, " "), errorFields(v))
+	fatalExit()
+}
+
+func (l *Logger) Fatalf(msg string, v ...interface{}) {
+	l.output(FatalLevel, fmt.Sprintf(msg, v...), errorFields(v))
+	fatalExit()
+}
+
+// Fatalw logs msg with the given key-value pairs as fields and exits.
+func (l *Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.output(FatalLevel, msg, append(toFields(keysAndValues), errorFields(keysAndValues)...))
+	fatalExit()
+}
@@ -0,0 +1,146 @@
+package superLog
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Recorder is a sink keeping every entry in memory, for tests asserting on
+// what was logged.
+type Recorder struct {
+	lock    sync.Mutex
+	entries []Entry
+}
+
+// TestingT is the part of testing.TB used by Record.
+type TestingT interface {
+	Helper()
+	Cleanup(func())
+}
+
+// ExitError is the panic raised by Fatal while a Recorder is installed,
+// instead of exiting the test binary.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("superLog: exit with code %d", e.Code)
+}
+
+// NewRecorder returns an empty recorder. Use Record in tests, or AddSink
+// to record next to the existing sinks.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Record replaces all sinks with a new Recorder for the duration of the test.
+// Fatal panics with *ExitError instead of exiting, see CatchExit.
+// Previous sinks and exit behaviour are restored when the test ends.
+func Record(t TestingT) *Recorder {
+	t.Helper()
+	r := NewRecorder()
+
+	sinksMu.Lock()
+	oldSinks := sinks
+	sinks = []Sink{r}
+	oldExit := exit
+	exit = func(code int) {
+		panic(&ExitError{Code: code})
+	}
+	sinksMu.Unlock()
+
+	t.Cleanup(func() {
+		sinksMu.Lock()
+		defer sinksMu.Unlock()
+		sinks = oldSinks
+		exit = oldExit
+	})
+	return r
+}
+
+// CatchExit runs f and reports the code passed to exit by Fatal, if any.
+// It only works while a Recorder is installed with Record.
+func CatchExit(f func()) (code int, exited bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*ExitError)
+			if !ok {
+				panic(r)
+			}
+			code, exited = e.Code, true
+		}
+	}()
+	f()
+	return 0, false
+}
+
+func (r *Recorder) Write(e *Entry) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	entry := *e
+	entry.Fields = append([]Field(nil), e.Fields...)
+	r.entries = append(r.entries, entry)
+	return nil
+}
+
+func (r *Recorder) Close() error {
+	return nil
+}
+
+// Entries returns a copy of all the recorded entries.
+// Entries still in the async buffer are flushed first.
+func (r *Recorder) Entries() []Entry {
+	Flush()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Level returns the recorded entries at lvl.
+func (r *Recorder) Level(lvl Level) []Entry {
+	var ret []Entry
+	for _, e := range r.Entries() {
+		if e.Level == lvl {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
+
+// Messages returns the message of every recorded entry.
+func (r *Recorder) Messages() []string {
+	var ret []string
+	for _, e := range r.Entries() {
+		ret = append(ret, e.Message)
+	}
+	return ret
+}
+
+// Contains reports whether an entry at lvl has a message containing substr.
+func (r *Recorder) Contains(lvl Level, substr string) bool {
+	for _, e := range r.Level(lvl) {
+		if strings.Contains(e.Message, substr) {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset drops all the recorded entries.
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.entries = nil
+}
+
+// Field returns the value of the last field named key.
+func (e Entry) Field(key string) (interface{}, bool) {
+	for i := len(e.Fields) - 1; i >= 0; i-- {
+		if e.Fields[i].Key == key {
+			return e.Fields[i].Value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,45 @@
+package superLog
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRecord(t *testing.T) {
+	r := Record(t)
+
+	With("topic", "orders").Errorw("Decode kafka message", "offset", 42)
+	Debug("dropped by level")
+
+	entries := r.Entries()
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Level != ErrorLevel || e.Message != "Decode kafka message" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if v, ok := e.Field("offset"); !ok || v != 42 {
+		t.Errorf("offset field = %v, %v", v, ok)
+	}
+	if v, _ := e.Field("topic"); v != "orders" {
+		t.Errorf("topic field = %v", v)
+	}
+	if filepath.Base(e.Caller.File) != "recorder_test.go" {
+		t.Errorf("caller = %s", e.Caller.File)
+	}
+}
+
+func TestCatchExit(t *testing.T) {
+	r := Record(t)
+
+	code, exited := CatchExit(func() {
+		Fatal("Missing", "serviceName")
+	})
+	if !exited || code != 1 {
+		t.Fatalf("exited = %v, code = %d", exited, code)
+	}
+	if !r.Contains(FatalLevel, "Missing serviceName") {
+		t.Errorf("fatal entry not recorded: %v", r.Messages())
+	}
+}
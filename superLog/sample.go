@@ -0,0 +1,154 @@
+package superLog
+
+import (
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplingConfig limits how many entries a single call site writes.
+// In every Interval the first First entries are written, then only every
+// Thereafter-th one. Dropped entries are counted and reported once every
+// SummaryInterval.
+type SamplingConfig struct {
+	Interval        time.Duration
+	First           int
+	Thereafter      int
+	SummaryInterval time.Duration
+}
+
+const defaultSummaryInterval = time.Minute
+
+// sampling holds a *SamplingConfig, nil when sampling is disabled.
+var sampling atomic.Value
+
+// SetSampling enables per call site sampling, nil disables it.
+// Fatal entries are never sampled.
+func SetSampling(cfg *SamplingConfig) {
+	if cfg != nil {
+		c := *cfg
+		if c.Interval <= 0 {
+			c.Interval = time.Second
+		}
+		if c.SummaryInterval <= 0 {
+			c.SummaryInterval = defaultSummaryInterval
+		}
+		cfg = &c
+	}
+	sampling.Store(cfg)
+}
+
+func getSampling() *SamplingConfig {
+	cfg, _ := sampling.Load().(*SamplingConfig)
+	return cfg
+}
+
+// Every returns a logger writing at most once per d from each call site,
+// e.g. lg.Every(time.Second).Error("Read kafka", err) in a retry loop.
+func Every(d time.Duration) *Logger {
+	return std.Every(d)
+}
+
+// Every returns a copy of l writing at most once per d from each call site.
+func (l *Logger) Every(d time.Duration) *Logger {
+	child := l.clone()
+	child.every = d
+	return child
+}
+
+type siteKey struct {
+	pc    uintptr
+	every time.Duration
+}
+
+// site is the sampling state of a single call site.
+type site struct {
+	lock        sync.Mutex
+	windowStart time.Time
+	count       int
+	lastWrite   time.Time
+	dropped     int
+	pc          uintptr
+}
+
+var (
+	sites       sync.Map
+	summaryOnce sync.Once
+)
+
+// sample reports whether the entry logged at pc should be written.
+func sample(pc uintptr, lvl Level, every time.Duration) bool {
+	if lvl >= FatalLevel {
+		return true
+	}
+	cfg := getSampling()
+	if every <= 0 && cfg == nil {
+		return true
+	}
+
+	val, _ := sites.LoadOrStore(siteKey{pc: pc, every: every}, &site{pc: pc})
+	s := val.(*site)
+	now := time.Now()
+
+	s.lock.Lock()
+	keep := true
+	if every > 0 {
+		keep = now.Sub(s.lastWrite) >= every
+	} else {
+		if now.Sub(s.windowStart) >= cfg.Interval {
+			s.windowStart = now
+			s.count = 0
+		}
+		s.count++
+		keep = s.count <= cfg.First ||
+			(cfg.Thereafter > 0 && (s.count-cfg.First)%cfg.Thereafter == 0)
+	}
+	if keep {
+		s.lastWrite = now
+	} else {
+		s.dropped++
+	}
+	s.lock.Unlock()
+
+	if !keep {
+		summaryOnce.Do(func() { go reportDropped() })
+	}
+	return keep
+}
+
+// reportDropped periodically writes how many entries each call site dropped.
+func reportDropped() {
+	for {
+		interval := defaultSummaryInterval
+		if cfg := getSampling(); cfg != nil {
+			interval = cfg.SummaryInterval
+		}
+		time.Sleep(interval)
+
+		sites.Range(func(_, val interface{}) bool {
+			s := val.(*site)
+			s.lock.Lock()
+			dropped := s.dropped
+			s.dropped = 0
+			s.lock.Unlock()
+			if dropped == 0 {
+				return true
+			}
+			frame := frameOf(s.pc)
+			write(&Entry{
+				Time:    time.Now(),
+				Level:   WarnLevel,
+				Caller:  frame,
+				Message: "Dropped log entries by sampling",
+				Fields: []Field{
+					{Key: "caller", Value: filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)},
+					{Key: "dropped", Value: dropped},
+					{Key: "interval", Value: interval.String()},
+				},
+			})
+			return true
+		})
+	}
+}
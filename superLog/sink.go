@@ -0,0 +1,145 @@
+package superLog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/natefinch/lumberjack"
+)
+
+// Sink is a destination for log entries. Every entry which passes the
+// global level is handed to every sink.
+type Sink interface {
+	Write(e *Entry) error
+	Close() error
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   = []Sink{NewConsoleSink()}
+)
+
+// SetSinks replaces all the sinks, closing the previous ones.
+func SetSinks(ss ...Sink) {
+	sinksMu.Lock()
+	old := sinks
+	sinks = ss
+	sinksMu.Unlock()
+
+	for _, s := range old {
+		if err := s.Close(); err != nil {
+			fmt.Fprintln(os.Stderr, "superLog: close sink:", err)
+		}
+	}
+}
+
+// AddSink adds s to the sinks receiving every entry.
+func AddSink(s Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, s)
+}
+
+// writeSinks hands e to every sink. Sink errors are reported straight to
+// stderr, logging them would recurse into the failing sink.
+func writeSinks(e *Entry) {
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, s := range sinks {
+		if err := s.Write(e); err != nil {
+			fmt.Fprintln(os.Stderr, "superLog:", err)
+		}
+	}
+}
+
+type SinkOption func(*writerSink)
+
+// WithMinLevel drops entries below lvl for this sink only.
+func WithMinLevel(lvl Level) SinkOption {
+	return func(s *writerSink) {
+		s.minLevel = lvl
+	}
+}
+
+// WithEncoder makes the sink use enc instead of the global encoder.
+func WithEncoder(enc Encoder) SinkOption {
+	return func(s *writerSink) {
+		s.encoder = enc
+	}
+}
+
+// writerSink encodes entries and writes them to an io.Writer.
+// Entries at error level and above go to errWriter when it is set.
+type writerSink struct {
+	lock      sync.Mutex
+	w         io.Writer
+	errWriter io.Writer
+	minLevel  Level
+	encoder   Encoder
+}
+
+// NewWriterSink returns a sink writing to w.
+// If w is an io.Closer, it is closed with the sink.
+func NewWriterSink(w io.Writer, opts ...SinkOption) Sink {
+	s := &writerSink{w: w, minLevel: TraceLevel}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewConsoleSink returns the default sink: stdout, and stderr for error and fatal.
+func NewConsoleSink(opts ...SinkOption) Sink {
+	s := &writerSink{w: os.Stdout, errWriter: os.Stderr, minLevel: TraceLevel}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// NewFileSink returns a sink writing to filename, rotated by lumberjack once
+// it reaches maxSize megabytes. maxBackup and maxAge (days) limit the number
+// of old files kept, 0 keeps all of them.
+func NewFileSink(filename string, maxSize, maxBackup, maxAge int, opts ...SinkOption) Sink {
+	return NewWriterSink(&lumberjack.Logger{
+		Filename:   filename,
+		MaxSize:    maxSize,
+		MaxBackups: maxBackup,
+		MaxAge:     maxAge,
+	}, opts...)
+}
+
+func (s *writerSink) Write(e *Entry) error {
+	if e.Level < s.minLevel {
+		return nil
+	}
+	enc := s.encoder
+	if enc == nil {
+		enc = currentEncoder()
+	}
+	d, err := enc.Encode(e)
+	if err != nil {
+		return err
+	}
+
+	out := s.w
+	if s.errWriter != nil && e.Level >= ErrorLevel {
+		out = s.errWriter
+	}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	_, err = out.Write(d)
+	return err
+}
+
+func (s *writerSink) Close() error {
+	if s.w == os.Stdout || s.w == os.Stderr {
+		return nil
+	}
+	if c, ok := s.w.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+//go:build !windows && !plan9
+// +build !windows,!plan9
+
+package superLog
+
+import (
+	"log/syslog"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// syslogSink forwards entries to syslog with the matching priority.
+type syslogSink struct {
+	w        *syslog.Writer
+	minLevel Level
+	encoder  Encoder
+}
+
+// NewSyslogSink connects to the syslog daemon at raddr over network.
+// Empty network and raddr connect to the local syslog socket.
+// Only WithMinLevel and WithEncoder apply, the default encoder is plain text.
+func NewSyslogSink(network, raddr, tag string, opts ...SinkOption) (Sink, error) {
+	w, err := syslog.Dial(network, raddr, syslog.LOG_INFO|syslog.LOG_USER, tag)
+	if err != nil {
+		return nil, errors.Wrap(err, "Dial syslog")
+	}
+	cfg := &writerSink{minLevel: TraceLevel, encoder: &textEncoder{}}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return &syslogSink{w: w, minLevel: cfg.minLevel, encoder: cfg.encoder}, nil
+}
+
+func (s *syslogSink) Write(e *Entry) error {
+	if e.Level < s.minLevel {
+		return nil
+	}
+	d, err := s.encoder.Encode(e)
+	if err != nil {
+		return err
+	}
+	msg := strings.TrimSuffix(string(d), "\n")
+	switch e.Level {
+	case TraceLevel, DebugLevel:
+		return s.w.Debug(msg)
+	case InfoLevel:
+		return s.w.Info(msg)
+	case WarnLevel:
+		return s.w.Warning(msg)
+	case ErrorLevel:
+		return s.w.Err(msg)
+	default:
+		return s.w.Crit(msg)
+	}
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,11 @@
+//go:build windows || plan9
+// +build windows plan9
+
+package superLog
+
+import "github.com/pkg/errors"
+
+// NewSyslogSink is not supported on this platform.
+func NewSyslogSink(network, raddr, tag string, opts ...SinkOption) (Sink, error) {
+	return nil, errors.New("syslog is not supported on this platform")
+}
@@ -0,0 +1,160 @@
+package superLog
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Keys of the fields describing an error.
+const (
+	CausesKey = "causes"
+	StackKey  = "stacktrace"
+)
+
+// StackFrame is a single frame of a Stack.
+type StackFrame struct {
+	Function string `json:"func"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+}
+
+// Stack is a call stack, innermost frame first. It is written as an array
+// of frames in JSON and one frame per line in text.
+type Stack []StackFrame
+
+func (s Stack) String() string {
+	var b strings.Builder
+	for i, f := range s {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%s\n\t%s:%d", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+func (s Stack) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]StackFrame(s))
+}
+
+func stackFromPCs(pcs []uintptr) Stack {
+	var stack Stack
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		stack = append(stack, StackFrame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			break
+		}
+	}
+	return stack
+}
+
+// callerStack returns the stack skip levels above the function calling it.
+func callerStack(skip int) Stack {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	if n == 0 {
+		return nil
+	}
+	return stackFromPCs(pcs[:n])
+}
+
+type stackTracer interface {
+	StackTrace() errors.StackTrace
+}
+
+// errorStack returns the stack recorded by the innermost github.com/pkg/errors
+// error of the chain, which is the closest to where the error happened.
+func errorStack(err error) Stack {
+	var st errors.StackTrace
+	for ; err != nil; err = unwrap(err) {
+		if tracer, ok := err.(stackTracer); ok {
+			st = tracer.StackTrace()
+		}
+	}
+	if len(st) == 0 {
+		return nil
+	}
+	pcs := make([]uintptr, len(st))
+	for i, f := range st {
+		pcs[i] = uintptr(f)
+	}
+	return stackFromPCs(pcs)
+}
+
+// unwrap supports both Go 1.13 wrapping and github.com/pkg/errors causes.
+func unwrap(err error) error {
+	switch e := err.(type) {
+	case interface{ Unwrap() error }:
+		return e.Unwrap()
+	case interface{ Cause() error }:
+		return e.Cause()
+	default:
+		return nil
+	}
+}
+
+// errorChain returns the messages of err and of every error it wraps.
+// Wrappers which only add a stack are skipped since their message is the same.
+func errorChain(err error) []string {
+	var chain []string
+	for ; err != nil; err = unwrap(err) {
+		msg := err.Error()
+		if len(chain) > 0 && chain[len(chain)-1] == msg {
+			continue
+		}
+		chain = append(chain, msg)
+	}
+	return chain
+}
+
+// errorFields describes the first error found in args: the cause chain when
+// it wraps other errors, and its stack when it has one.
+func errorFields(args []interface{}) []Field {
+	for _, arg := range args {
+		err, ok := arg.(error)
+		if !ok || err == nil {
+			continue
+		}
+		var fields []Field
+		if chain := errorChain(err); len(chain) > 1 {
+			fields = append(fields, Field{Key: CausesKey, Value: chain[1:]})
+		}
+		if stack := errorStack(err); len(stack) > 0 {
+			fields = append(fields, Field{Key: StackKey, Value: stack})
+		}
+		return fields
+	}
+	return nil
+}
+
+var errorStacks int32
+
+// EnableErrorStack attaches the stack of the caller to every error and
+// fatal entry which does not already carry the stack of an error.
+func EnableErrorStack(enabled bool) {
+	var v int32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreInt32(&errorStacks, v)
+}
+
+func errorStackEnabled() bool {
+	return atomic.LoadInt32(&errorStacks) == 1
+}
+
+func hasField(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,165 @@
+package superLog
+
+import (
+	"path"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// vmoduleRule sets the level of the packages matching pattern.
+type vmoduleRule struct {
+	pattern string
+	level   Level
+}
+
+// vmodule is an immutable set of rules, swapped as a whole on SetVModule.
+type vmodule struct {
+	spec  string
+	rules []vmoduleRule
+	// levels caches the moduleLevel resolved for each caller pc.
+	levels sync.Map
+}
+
+// moduleLevel is the level of a package, ok is false when no rule matches
+// and the global level applies.
+type moduleLevel struct {
+	level Level
+	ok    bool
+}
+
+var currentVModule atomic.Value
+
+// SetVModule overrides the level of some packages, e.g.
+//
+//	superMongo=debug,superKafka=warn,myservice/*=info
+//
+// A pattern is matched with path.Match against every run of path elements
+// of the caller's package, so "superKafka" also covers superKafka/consumer.
+// The first matching rule wins, packages matching no rule use the global
+// level. An empty spec removes all the overrides.
+func SetVModule(spec string) error {
+	vm, err := parseVModule(spec)
+	if err != nil {
+		return err
+	}
+	currentVModule.Store(vm)
+	return nil
+}
+
+// GetVModule returns the spec set by SetVModule.
+func GetVModule() string {
+	if vm := getVModule(); vm != nil {
+		return vm.spec
+	}
+	return ""
+}
+
+func getVModule() *vmodule {
+	vm, _ := currentVModule.Load().(*vmodule)
+	if vm == nil || len(vm.rules) == 0 {
+		return nil
+	}
+	return vm
+}
+
+func parseVModule(spec string) (*vmodule, error) {
+	vm := &vmodule{spec: strings.TrimSpace(spec)}
+	if vm.spec == "" {
+		return vm, nil
+	}
+	for _, part := range strings.Split(vm.spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("invalid vmodule rule %q, expect pattern=level", part)
+		}
+		pattern := strings.Trim(strings.TrimSpace(kv[0]), "/")
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, errors.Wrapf(err, "invalid vmodule pattern %q", pattern)
+		}
+		lvl, err := ParseLevel(kv[1])
+		if err != nil {
+			return nil, err
+		}
+		vm.rules = append(vm.rules, vmoduleRule{pattern: pattern, level: lvl})
+	}
+	return vm, nil
+}
+
+// levelFor returns the minimum level for entries logged at pc.
+func (vm *vmodule) levelFor(pc uintptr) Level {
+	cached, hit := vm.levels.Load(pc)
+	if !hit {
+		var ml moduleLevel
+		if pkg := packagePath(frameOf(pc).Function); pkg != "" {
+			for _, rule := range vm.rules {
+				if matchPackage(rule.pattern, pkg) {
+					ml = moduleLevel{level: rule.level, ok: true}
+					break
+				}
+			}
+		}
+		cached, _ = vm.levels.LoadOrStore(pc, ml)
+	}
+	if ml := cached.(moduleLevel); ml.ok {
+		return ml.level
+	}
+	return GetLevel()
+}
+
+// minLevel is the lowest level enabled for any package.
+func (vm *vmodule) minLevel() Level {
+	lvl := GetLevel()
+	for _, rule := range vm.rules {
+		if rule.level < lvl {
+			lvl = rule.level
+		}
+	}
+	return lvl
+}
+
+// packagePath extracts the import path from a fully qualified function name
+// such as "github.com/superwhys/superGo/superMongo.(*Client).newSession".
+func packagePath(function string) string {
+	slash := strings.LastIndex(function, "/")
+	dot := strings.Index(function[slash+1:], ".")
+	if dot < 0 {
+		return function
+	}
+	return function[:slash+1+dot]
+}
+
+// matchPackage reports whether pattern matches a run of path elements of pkg.
+func matchPackage(pattern, pkg string) bool {
+	elems := strings.Split(pkg, "/")
+	n := strings.Count(pattern, "/") + 1
+	for i := 0; i+n <= len(elems); i++ {
+		if ok, _ := path.Match(pattern, strings.Join(elems[i:i+n], "/")); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// enabledAt reports whether an entry at lvl logged at pc is written,
+// honoring the vmodule overrides.
+func enabledAt(lvl Level, pc uintptr) bool {
+	if vm := getVModule(); vm != nil {
+		return lvl >= vm.levelFor(pc)
+	}
+	return Enabled(lvl)
+}
+
+// anyEnabled reports whether lvl is enabled for at least one package.
+func anyEnabled(lvl Level) bool {
+	if vm := getVModule(); vm != nil {
+		return lvl >= vm.minLevel()
+	}
+	return Enabled(lvl)
+}
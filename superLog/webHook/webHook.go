@@ -0,0 +1,131 @@
+package webHook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/superwhys/superGo/superHttp/httpClient"
+	"github.com/superwhys/superGo/superHttp/httpRequests"
+	"github.com/superwhys/superGo/superLog"
+)
+
+const defaultTimeout = time.Second * 10
+
+// Formatter builds the request body posted for a batch of entries.
+type Formatter func(entries []superLog.Entry) ([]byte, error)
+
+type OptionWebHookFunc func(*WebHook)
+
+// WebHook is a superLog.Hook posting entries as JSON to a chat webhook or
+// an incident endpoint.
+type WebHook struct {
+	url       string
+	client    *httpClient.HttpClient
+	timeout   time.Duration
+	formatter Formatter
+	opts      []httpRequests.OptionHttpRequestsFunc
+}
+
+// New returns a hook posting to url. By default the body is {"text": "..."}
+// with one line per entry, understood by most chat webhooks. Add it with
+//
+//	superLog.AddHook(superLog.ErrorLevel, webHook.New(url), superLog.WithHookDedup(time.Minute))
+func New(url string, opts ...OptionWebHookFunc) *WebHook {
+	wh := &WebHook{
+		url:       url,
+		client:    httpClient.Client,
+		timeout:   defaultTimeout,
+		formatter: TextFormatter,
+	}
+	for _, opt := range opts {
+		opt(wh)
+	}
+	return wh
+}
+
+func WithClient(client *httpClient.HttpClient) OptionWebHookFunc {
+	return func(wh *WebHook) {
+		wh.client = client
+	}
+}
+
+func WithTimeout(timeout time.Duration) OptionWebHookFunc {
+	return func(wh *WebHook) {
+		wh.timeout = timeout
+	}
+}
+
+func WithFormatter(formatter Formatter) OptionWebHookFunc {
+	return func(wh *WebHook) {
+		wh.formatter = formatter
+	}
+}
+
+// WithRequestOption applies opt, e.g. httpRequests.AddHeader, to every request.
+func WithRequestOption(opt httpRequests.OptionHttpRequestsFunc) OptionWebHookFunc {
+	return func(wh *WebHook) {
+		wh.opts = append(wh.opts, opt)
+	}
+}
+
+// TextFormatter renders entries as {"text": "[ERROR] main.go:12 message key=value"}.
+func TextFormatter(entries []superLog.Entry) ([]byte, error) {
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		var b strings.Builder
+		fmt.Fprintf(&b, "[%s] %s", e.Level.CapitalString(), e.Time.UTC().Format(time.RFC3339))
+		if e.Caller.File != "" {
+			fmt.Fprintf(&b, " %s:%d", e.Caller.File, e.Caller.Line)
+		}
+		b.WriteString(" ")
+		b.WriteString(e.Message)
+		for _, f := range e.Fields {
+			if f.Key == superLog.StackKey {
+				continue
+			}
+			fmt.Fprintf(&b, " %s=%v", f.Key, f.Value)
+		}
+		lines = append(lines, b.String())
+	}
+	return json.Marshal(map[string]string{"text": strings.Join(lines, "\n")})
+}
+
+// Fire posts the entries. It does not go through httpRequests.SuperRequests,
+// whose error logging would fire the hook again.
+func (wh *WebHook) Fire(entries []superLog.Entry) error {
+	body, err := wh.formatter(entries)
+	if err != nil {
+		return errors.Wrap(err, "Format entries")
+	}
+
+	if _, err := url.ParseRequestURI(wh.url); err != nil {
+		return errors.Wrap(err, "Invalid webhook url")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), wh.timeout)
+	defer cancel()
+	opts := append([]httpRequests.OptionHttpRequestsFunc{
+		httpRequests.AddHeader("Content-Type", "application/json"),
+	}, wh.opts...)
+	req := httpRequests.InitRequests("POST", wh.url, ctx, bytes.NewReader(body), opts...)
+	resp, err := wh.client.Client.Do(req.Requests)
+	if err != nil {
+		return errors.Wrap(err, "Post webhook")
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.Wrap(err, "Read webhook response")
+	}
+	// Incident endpoints often answer 202 or 204.
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("Post webhook: %s[%d]:%s", resp.Status, resp.StatusCode, string(data))
+	}
+	return nil
+}
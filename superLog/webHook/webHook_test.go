@@ -0,0 +1,79 @@
+package webHook
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/superwhys/superGo/superLog"
+)
+
+func TestWebHook(t *testing.T) {
+	var (
+		lock   sync.Mutex
+		bodies []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		lock.Lock()
+		bodies = append(bodies, payload["text"])
+		lock.Unlock()
+	}))
+	defer srv.Close()
+
+	superLog.Record(t)
+	remove := superLog.AddHook(superLog.ErrorLevel, New(srv.URL),
+		superLog.WithHookBatch(10, time.Hour),
+		superLog.WithHookDedup(time.Hour),
+	)
+	defer remove()
+
+	superLog.Info("not an alert")
+	for i := 0; i < 3; i++ {
+		superLog.Error("Mongo pool exhausted")
+	}
+	superLog.Errorw("Decode kafka message", "offset", 42)
+	superLog.Flush()
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(bodies) != 1 {
+		t.Fatalf("got %d requests, want 1 batch", len(bodies))
+	}
+	lines := strings.Split(bodies[0], "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2 after dedup: %q", len(lines), bodies[0])
+	}
+	if !strings.Contains(lines[0], "Mongo pool exhausted") || !strings.Contains(lines[1], "offset=42") {
+		t.Errorf("unexpected body: %q", bodies[0])
+	}
+}
+
+func TestWebHookStatus(t *testing.T) {
+	for _, tc := range []struct {
+		status  int
+		wantErr bool
+	}{
+		{http.StatusOK, false},
+		{http.StatusAccepted, false},
+		{http.StatusNoContent, false},
+		{http.StatusBadRequest, true},
+		{http.StatusInternalServerError, true},
+	} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tc.status)
+		}))
+		err := New(srv.URL).Fire([]superLog.Entry{{Level: superLog.ErrorLevel, Message: "boom"}})
+		srv.Close()
+		if (err != nil) != tc.wantErr {
+			t.Errorf("status %d: err = %v, want error %v", tc.status, err, tc.wantErr)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package superLog
+
+import (
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// ZapLevel returns the closest zap level. Trace has no zap equivalent and
+// maps to debug.
+func (l Level) ZapLevel() zapcore.Level {
+	switch l {
+	case TraceLevel, DebugLevel:
+		return zapcore.DebugLevel
+	case InfoLevel:
+		return zapcore.InfoLevel
+	case WarnLevel:
+		return zapcore.WarnLevel
+	case ErrorLevel:
+		return zapcore.ErrorLevel
+	default:
+		return zapcore.FatalLevel
+	}
+}
+
+// LevelFromZap returns the level matching a zap level.
+// DPanic and Panic are reported as error, zap panics on its own.
+func LevelFromZap(zl zapcore.Level) Level {
+	switch zl {
+	case zapcore.DebugLevel:
+		return DebugLevel
+	case zapcore.InfoLevel:
+		return InfoLevel
+	case zapcore.WarnLevel:
+		return WarnLevel
+	case zapcore.ErrorLevel, zapcore.DPanicLevel, zapcore.PanicLevel:
+		return ErrorLevel
+	default:
+		return FatalLevel
+	}
+}
+
+// zapSink writes superLog entries into a zap core.
+type zapSink struct {
+	core zapcore.Core
+}
+
+// NewZapSink returns a sink writing every entry into core, so superLog
+// output follows the encoder, outputs and level of a zap configuration.
+// Do not combine it with a zap logger built on NewZapCore, entries would loop.
+func NewZapSink(core zapcore.Core) Sink {
+	return &zapSink{core: core}
+}
+
+func (s *zapSink) Write(e *Entry) error {
+	ent := zapcore.Entry{
+		Level:   e.Level.ZapLevel(),
+		Time:    e.Time,
+		Message: e.Message,
+		Caller:  zapcore.NewEntryCaller(e.Caller.PC, e.Caller.File, e.Caller.Line, e.Caller.File != ""),
+	}
+	if !s.core.Enabled(ent.Level) {
+		return nil
+	}
+	fields := make([]zapcore.Field, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		fields = append(fields, zap.Any(f.Key, f.Value))
+	}
+	return s.core.Write(ent, fields)
+}
+
+func (s *zapSink) Sync() error {
+	return s.core.Sync()
+}
+
+func (s *zapSink) Close() error {
+	return s.core.Sync()
+}
+
+// zapCore is a zapcore.Core routing zap entries into superLog, so that code
+// calling zap.L() honors the superLog level, encoder and sinks.
+type zapCore struct {
+	fields []Field
+}
+
+// NewZapCore returns a core writing into superLog, use it as
+// zap.ReplaceGlobals(zap.New(superLog.NewZapCore(), zap.AddCaller())).
+func NewZapCore() zapcore.Core {
+	return &zapCore{}
+}
+
+func (c *zapCore) Enabled(zl zapcore.Level) bool {
+	return anyEnabled(LevelFromZap(zl))
+}
+
+func (c *zapCore) With(fields []zapcore.Field) zapcore.Core {
+	return &zapCore{fields: append(append([]Field(nil), c.fields...), zapFields(fields)...)}
+}
+
+func (c *zapCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *zapCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	// Enabled lets through the lowest level of the vmodule rules, check the
+	// level of the caller, or the global level without one.
+	if ent.Caller.Defined {
+		if !enabledAt(LevelFromZap(ent.Level), ent.Caller.PC) {
+			return nil
+		}
+	} else if !Enabled(LevelFromZap(ent.Level)) {
+		return nil
+	}
+	all := append(append([]Field(nil), c.fields...), zapFields(fields)...)
+	if ent.LoggerName != "" {
+		all = append(all, Field{Key: "logger", Value: ent.LoggerName})
+	}
+	if ent.Stack != "" {
+		all = append(all, Field{Key: "stacktrace", Value: ent.Stack})
+	}
+	var frame runtime.Frame
+	if ent.Caller.Defined {
+		frame = runtime.Frame{
+			PC:       ent.Caller.PC,
+			File:     ent.Caller.File,
+			Line:     ent.Caller.Line,
+			Function: ent.Caller.Function,
+		}
+	}
+	write(&Entry{
+		Time:    ent.Time,
+		Level:   LevelFromZap(ent.Level),
+		Caller:  frame,
+		Message: ent.Message,
+		Fields:  all,
+	})
+	if ent.Level == zapcore.FatalLevel {
+		// zap exits on its own right after this write.
+		runExitHooks()
+		Flush()
+	}
+	return nil
+}
+
+func (c *zapCore) Sync() error {
+	return nil
+}
+
+// zapFields converts zap fields keeping their order.
+func zapFields(fields []zapcore.Field) []Field {
+	ret := make([]Field, 0, len(fields))
+	for _, f := range fields {
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		for k, v := range enc.Fields {
+			ret = append(ret, Field{Key: k, Value: v})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,24 @@
+package superLog
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestZapCoreVModule(t *testing.T) {
+	r := Record(t)
+	if err := SetVModule("superMongo=debug"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetVModule("")
+
+	zap.New(NewZapCore()).Debug("no caller")
+	zap.New(NewZapCore(), zap.AddCaller()).Debug("caller outside superMongo")
+	zap.New(NewZapCore()).Info("info")
+
+	if got, want := r.Messages(), []string{"info"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("messages = %q, want %q", got, want)
+	}
+}
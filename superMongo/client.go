@@ -143,7 +143,7 @@ func (c *Client) Open(db, collection string) *Collection {
 func (c *Client) OpenWithContext(ctx context.Context, db, collection string) *Collection {
 	session, err := c.newSession()
 	if err != nil {
-		lg.Error("Failed to dial mongodb", c.url, db, collection, err)
+		lg.ErrorCtx(ctx, "Failed to dial mongodb", c.url, db, collection, err)
 		return nil
 	}
 	connKey := fmt.Sprintf("%s.%s", db, collection)
@@ -202,7 +202,7 @@ func (c *Client) newSession() (*mgo.Session, error) {
 	select {
 	case c.worker <- 1:
 	default:
-		lg.Error("Mongo worker has exceed mongo pool size (", c.poolSize, "), please check if any mongo connection is leaking.\n", lg.Jsonify(c.statConnections()))
+		lg.Every(time.Second).Error("Mongo worker has exceed mongo pool size (", c.poolSize, "), please check if any mongo connection is leaking.\n", lg.Jsonify(c.statConnections()))
 		c.worker <- 1
 	}
 	return c.session.Copy(), nil
@@ -12,6 +12,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/natefinch/lumberjack"
 	"github.com/spf13/viper"
+	"github.com/superwhys/superGo/superLog"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -30,9 +31,25 @@ func Init() (err error) {
 		return
 	}
 	core := zapcore.NewCore(logEncoder, logWriter, logLevel)
-	lg := zap.New(core, zap.AddCaller())
-	// 替换zap全局的logger
-	zap.ReplaceGlobals(lg)
+
+	// log.backend 决定整个服务使用哪一套日志配置, zap.L() 和 superLog 的输出保持一致
+	superLog.SetLevel(superLog.LevelFromZap(*logLevel))
+	switch viper.GetString("log.backend") {
+	case "superLog":
+		// zap.L() 写入superLog, 使用superLog的格式和输出
+		superLog.SetSinks(superLog.NewFileSink(
+			viper.GetString("log.filename"),
+			viper.GetInt("log.max_size"),
+			viper.GetInt("log.max_backup"),
+			viper.GetInt("log.max_age"),
+		))
+		zap.ReplaceGlobals(zap.New(superLog.NewZapCore(), zap.AddCaller()))
+	default:
+		// superLog 写入zap core, 使用zap的格式和输出
+		superLog.SetSinks(superLog.NewZapSink(core))
+		// 替换zap全局的logger
+		zap.ReplaceGlobals(zap.New(core, zap.AddCaller()))
+	}
 	return
 }
 
@@ -11,6 +11,7 @@ import (
 
 	"github.com/spf13/viper"
 
+	"github.com/superwhys/superGo/superLog"
 	"github.com/superwhys/superGo/superWeb/dao/mysql"
 	"github.com/superwhys/superGo/superWeb/dao/redis"
 	"github.com/superwhys/superGo/superWeb/logger"
@@ -33,18 +34,21 @@ func main() {
 	}
 	// 将缓冲区的日志写入文件
 	defer zap.L().Sync()
+	superLog.RegisterExitHook(func() { _ = zap.L().Sync() })
 	// 3. 初始化Mysql连接
 	if err := mysql.Init(); err != nil {
 		zap.L().Error("connect mysql failed", zap.Error(err))
 		return
 	}
 	defer mysql.Close()
+	superLog.RegisterExitHook(mysql.Close)
 	// 4. 初始化Redis连接
 	if err := redis.Init(); err != nil {
 		zap.L().Error("connect redis failed", zap.Error(err))
 		return
 	}
 	defer redis.Close()
+	superLog.RegisterExitHook(redis.Close)
 	// 5. 注册路由
 	router := routes.SetUp()
 	// 6. 启动服务（优雅关机）